@@ -0,0 +1,421 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/client"
+	pb "github.com/dikaeinstein/prototodo/pkg/proto"
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+	"github.com/dikaeinstein/prototodo/pkg/todo/codec"
+	"github.com/dikaeinstein/prototodo/pkg/tui"
+	"github.com/gdamore/tcell"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// command is a subcommand of the client.
+type command struct {
+	usage string
+	help  string
+	run   func(ctx context.Context, e *env, args []string) error
+	// interactive commands run until the user quits rather than within
+	// the -timeout, which applies to each of their RPCs instead.
+	interactive bool
+	// transfer commands stream any number of todos, so they run until
+	// they are done rather than within the -timeout.
+	transfer bool
+}
+
+// env is what commands run with.
+type env struct {
+	conn    *grpc.ClientConn
+	client  *client.Client
+	out     *printer
+	timeout time.Duration
+}
+
+var commands map[string]*command
+
+func init() {
+	commands = map[string]*command{
+		"add": {
+			usage: "add -title <title> [-description <text>] [-priority <A-Z>] [-reminder <time>]",
+			help:  "Create a todo",
+			run:   runAdd,
+		},
+		"get": {
+			usage: "get <id>",
+			help:  "Show a todo",
+			run:   runGet,
+		},
+		"list": {
+			usage: "list",
+			help:  "List all todos",
+			run:   runList,
+		},
+		"edit": {
+			usage: "edit <id> [-title <title>] [-description <text>] [-priority <A-Z>] [-reminder <time>]",
+			help:  "Change the given fields of a todo",
+			run:   runEdit,
+		},
+		"rm": {
+			usage: "rm <id>...",
+			help:  "Delete todos",
+			run:   runRm,
+		},
+		"import": {
+			usage:    "import [-format <format>] [-map <column>=<field>,...] [-dry_run] [<file>|-]",
+			help:     "Create todos from a JSON Lines, CSV or todo.txt file, reporting the lines that failed",
+			run:      runImport,
+			transfer: true,
+		},
+		"export": {
+			usage:    "export [-format <format>] [<file>]",
+			help:     "Write all todos to a JSON Lines, CSV or todo.txt file",
+			run:      runExport,
+			transfer: true,
+		},
+		"health": {
+			usage: "health",
+			help:  "Check that the server is serving",
+			run:   runHealth,
+		},
+		"tui": {
+			usage:       "tui [-refresh <interval>]",
+			help:        "Browse and edit todos in a full-screen terminal UI",
+			run:         runTUI,
+			interactive: true,
+		},
+	}
+}
+
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// usageError is returned for invalid command arguments.
+type usageError struct {
+	cmd *command
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func usageErrorf(name, format string, args ...interface{}) error {
+	return usageError{commands[name], fmt.Errorf(format, args...)}
+}
+
+// todoFlags are the flags setting the fields of a todo.
+type todoFlags struct {
+	fs          *flag.FlagSet
+	title       string
+	description string
+	priority    string
+	reminder    string
+}
+
+func newTodoFlags(name string) *todoFlags {
+	f := &todoFlags{fs: flag.NewFlagSet(name, flag.ContinueOnError)}
+	f.fs.SetOutput(ioutil.Discard)
+	f.fs.StringVar(&f.title, "title", "", "The title")
+	f.fs.StringVar(&f.description, "description", "", "The description")
+	f.fs.StringVar(&f.priority, "priority", "", "The priority, a letter from A, the highest, to Z")
+	f.fs.StringVar(&f.reminder, "reminder", "", "When to be reminded: RFC 3339, 2006-01-02 15:04 local time, or a duration from now such as 2h")
+	return f
+}
+
+// todo returns a todo with the fields of the flags that were set.
+func (f *todoFlags) todo(name string) (todo.Todo, error) {
+	t := todo.Todo{Title: f.title, Description: f.description, Priority: strings.ToUpper(f.priority)}
+	if f.reminder != "" {
+		r, err := client.ParseReminder(f.reminder, time.Now())
+		if err != nil {
+			return todo.Todo{}, usageErrorf(name, "invalid reminder: %v", err)
+		}
+		t.Reminder = r
+	}
+	return t, nil
+}
+
+func parseID(name, s string) (uint, error) {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || id == 0 {
+		return 0, usageErrorf(name, "invalid id %q", s)
+	}
+	return uint(id), nil
+}
+
+func runAdd(ctx context.Context, e *env, args []string) error {
+	f := newTodoFlags("add")
+	if err := f.fs.Parse(args); err != nil {
+		return usageErrorf("add", "%v", err)
+	}
+	if f.title == "" {
+		return usageErrorf("add", "-title is required")
+	}
+	t, err := f.todo("add")
+	if err != nil {
+		return err
+	}
+
+	created, err := e.client.Create(ctx, t)
+	if err != nil {
+		return err
+	}
+	return e.out.todo(created)
+}
+
+func runGet(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("get", "expected one id")
+	}
+	id, err := parseID("get", args[0])
+	if err != nil {
+		return err
+	}
+
+	t, err := e.client.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+	return e.out.todo(t)
+}
+
+func runList(ctx context.Context, e *env, args []string) error {
+	if len(args) != 0 {
+		return usageErrorf("list", "unexpected arguments %v", args)
+	}
+
+	tt, err := e.client.List(ctx).All()
+	if err != nil {
+		return err
+	}
+	return e.out.todos(tt)
+}
+
+func runEdit(ctx context.Context, e *env, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("edit", "expected an id")
+	}
+	id, err := parseID("edit", args[0])
+	if err != nil {
+		return err
+	}
+	f := newTodoFlags("edit")
+	if err := f.fs.Parse(args[1:]); err != nil {
+		return usageErrorf("edit", "%v", err)
+	}
+	if f.fs.NFlag() == 0 {
+		return usageErrorf("edit", "nothing to change")
+	}
+	t, err := f.todo("edit")
+	if err != nil {
+		return err
+	}
+
+	updated, err := e.client.Update(ctx, id, t)
+	if err != nil {
+		return err
+	}
+	return e.out.todo(updated)
+}
+
+func runRm(ctx context.Context, e *env, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("rm", "expected at least one id")
+	}
+	ids := make([]uint, len(args))
+	for i, arg := range args {
+		id, err := parseID("rm", arg)
+		if err != nil {
+			return err
+		}
+		ids[i] = id
+	}
+
+	for _, id := range ids {
+		if err := e.client.Delete(ctx, id); err != nil {
+			return status.Errorf(status.Code(err), "failed to delete %d: %s", id, status.Convert(err).Message())
+		}
+		fmt.Printf("deleted %d\n", id)
+	}
+	return nil
+}
+
+func runHealth(ctx context.Context, e *env, args []string) error {
+	resp, err := grpc_health_v1.NewHealthClient(e.conn).Check(ctx,
+		&grpc_health_v1.HealthCheckRequest{Service: "TodoService"})
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return status.Error(codes.Unimplemented, "the server doesn't implement the grpc health protocol")
+		}
+		return err
+	}
+
+	fmt.Println(resp.Status)
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		return status.Errorf(codes.Unavailable, "server is %s", resp.Status)
+	}
+	return nil
+}
+
+func runTUI(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("tui", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	refresh := fs.Duration("refresh", 5*time.Second, "How often the todos are reloaded, never if 0")
+	if err := fs.Parse(args); err != nil {
+		return usageErrorf("tui", "%v", err)
+	}
+	if fs.NArg() != 0 {
+		return usageErrorf("tui", "unexpected arguments %v", fs.Args())
+	}
+
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return status.Errorf(codes.FailedPrecondition, "failed to open the terminal: %v", err)
+	}
+	if err := screen.Init(); err != nil {
+		return status.Errorf(codes.FailedPrecondition, "failed to open the terminal: %v", err)
+	}
+	defer screen.Fini()
+
+	return tui.New(pb.NewTodoServiceClient(e.conn), screen, e.timeout, *refresh).Run(ctx)
+}
+
+func runImport(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	format := fs.String("format", "", "The format of the file: "+strings.Join(codec.Formats, ", ")+", from its extension if empty")
+	mapping := fs.String("map", "", "Maps CSV columns to fields, e.g. due=reminder,notes=description")
+	dryRun := fs.Bool("dry_run", false, "Validate the todos without creating them")
+	if err := fs.Parse(args); err != nil {
+		return usageErrorf("import", "%v", err)
+	}
+	if fs.NArg() > 1 {
+		return usageErrorf("import", "expected at most one file")
+	}
+	path := fs.Arg(0)
+
+	columns, err := parseColumns(*mapping)
+	if err != nil {
+		return usageErrorf("import", "invalid -map: %v", err)
+	}
+	if *format == "" {
+		var ok bool
+		if *format, ok = codec.FormatFromPath(path); !ok {
+			return usageErrorf("import", "-format is required unless the file has a .jsonl, .csv or .txt extension")
+		}
+	}
+
+	r := os.Stdin
+	if path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return status.Errorf(codes.NotFound, "%v", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	dec, err := codec.NewDecoder(*format, r, codec.DecodeOptions{Columns: columns})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res, err := e.client.Import(ctx, dec, *dryRun)
+	if err != nil {
+		return err
+	}
+	for _, lerr := range res.Errors {
+		fmt.Fprintln(os.Stderr, lerr)
+	}
+	if res.DryRun {
+		fmt.Printf("would import %d todos, %d failed\n", res.Imported, len(res.Errors))
+	} else {
+		fmt.Printf("imported %d todos, %d failed\n", res.Imported, len(res.Errors))
+	}
+	if len(res.Errors) > 0 {
+		return status.Errorf(codes.InvalidArgument, "%d lines weren't imported", len(res.Errors))
+	}
+	return nil
+}
+
+// parseColumns parses a comma-separated list of column=field.
+func parseColumns(s string) (map[string]string, error) {
+	columns := make(map[string]string)
+	if s == "" {
+		return columns, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, fmt.Errorf("%q is not column=field", pair)
+		}
+		columns[kv[0]] = strings.ToLower(kv[1])
+	}
+	return columns, nil
+}
+
+func runExport(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	format := fs.String("format", "", "The format of the file: "+strings.Join(codec.Formats, ", ")+", from its extension if empty, else jsonl")
+	if err := fs.Parse(args); err != nil {
+		return usageErrorf("export", "%v", err)
+	}
+	if fs.NArg() > 1 {
+		return usageErrorf("export", "expected at most one file")
+	}
+	path := fs.Arg(0)
+
+	if *format == "" {
+		var ok bool
+		if *format, ok = codec.FormatFromPath(path); !ok {
+			*format = codec.JSONL
+		}
+	}
+
+	w := os.Stdout
+	if path != "" && path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	enc, err := codec.NewEncoder(*format, w, nil)
+	if err != nil {
+		return usageErrorf("export", "%v", err)
+	}
+
+	it := e.client.Export(ctx)
+	for it.Next() {
+		if err := enc.Encode(it.Todo()); err != nil {
+			return status.Errorf(codes.Internal, "failed to write todo: %v", err)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return status.Errorf(codes.Internal, "failed to write todos: %v", err)
+	}
+	return nil
+}
@@ -2,132 +2,183 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
-	"time"
+	"os"
+	"strings"
 
+	"github.com/dikaeinstein/prototodo/pkg/client"
 	"github.com/dikaeinstein/prototodo/pkg/config"
-	pb "github.com/dikaeinstein/prototodo/pkg/proto"
-	"github.com/golang/protobuf/ptypes"
+	"github.com/dikaeinstein/prototodo/pkg/logger"
+	"github.com/dikaeinstein/prototodo/pkg/tracing"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
 )
 
-var defaultTimeout = 5 * time.Second
-
-func createTodo(client pb.TodoServiceClient, t pb.Todo) pb.Todo {
-	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
-	defer cancel()
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
 
-	resp, err := client.Create(ctx, &pb.CreateRequest{Todo: &t})
+// run runs the command in args and returns the exit code, which is
+// the code of the gRPC status the command failed with.
+func run(args []string) int {
+	configFile := config.FileFromArgs(args)
+	cfg, loadErr := config.Load(configFile)
+
+	fs := flag.NewFlagSet("client", flag.ContinueOnError)
+	fs.Usage = func() { usage(fs) }
+	fs.String("config", configFile, "The YAML or TOML config file, also read from $"+config.FileEnv)
+	fs.StringVar(&cfg.Server, "server", cfg.Server, "The server address, localhost:$PORT if empty")
+	fs.BoolVar(&cfg.TLS, "tls", cfg.TLS, "Connect over TLS")
+	fs.StringVar(&cfg.RootCert, "root_cert", cfg.RootCert, "The CA certificate the server certificate is verified against")
+	fs.StringVar(&cfg.Token, "token", cfg.Token, "The bearer token to authenticate with")
+	fs.StringVar(&cfg.ClientCertFile, "cert_file", cfg.ClientCertFile, "The client certificate for mutual TLS")
+	fs.StringVar(&cfg.ClientKeyFile, "key_file", cfg.ClientKeyFile, "The client key for mutual TLS")
+	fs.DurationVar(&cfg.Timeout, "timeout", cfg.Timeout, "How long a command, other than import and export, or each RPC of the tui, may take")
+	fs.IntVar(&cfg.RetryMaxAttempts, "retry_max_attempts", cfg.RetryMaxAttempts, "How many times calls are attempted while the server is unavailable, 1 to disable retries")
+	fs.DurationVar(&cfg.RetryInitialBackoff, "retry_initial_backoff", cfg.RetryInitialBackoff, "The backoff before the first retry, doubling with each further retry")
+	fs.DurationVar(&cfg.RetryMaxBackoff, "retry_max_backoff", cfg.RetryMaxBackoff, "The longest backoff between retries")
+	fs.StringVar(&cfg.LogLevel, "log_level", cfg.LogLevel, "The level of the logs written to stderr, such as retries: debug, info, warn or error")
+	output := fs.String("o", "table", "The output format: "+strings.Join(outputFormats, ", "))
+	tmpl := fs.String("template", "", "The Go template todos are printed with by -o template, e.g. '{{.ID}} {{.Title}}'")
+	timeMode := fs.String("time", "local", "Show times in the local timezone or relative to now: local or relative")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return int(codes.InvalidArgument)
+	}
+	if err := config.Join(loadErr, cfg.ValidateClient()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return int(codes.InvalidArgument)
+	}
+	if fs.NArg() == 0 {
+		usage(fs)
+		return int(codes.InvalidArgument)
+	}
+	out, err := newPrinter(os.Stdout, *output, *tmpl, *timeMode)
 	if err != nil {
-		log.Fatalf("%v.Create(_) = _, %v: ", client, err)
+		fmt.Fprintln(os.Stderr, err)
+		return int(codes.InvalidArgument)
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", fs.Arg(0))
+		usage(fs)
+		return int(codes.InvalidArgument)
 	}
 
-	log.Println("Create result: ", resp.GetTodo())
-	return *resp.Todo
-}
-
-func readTodo(client pb.TodoServiceClient, id int64) {
-	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
-	defer cancel()
-
-	resp, err := client.Read(ctx, &pb.ReadRequest{Id: id})
+	// Stdout and stderr are the output and logs of commands, so spans are
+	// only exported to a collector.
+	shutdownTracing, err := tracing.Init(context.Background(), "prototodo-client", cfg.OTLPEndpoint, nil)
 	if err != nil {
-		log.Fatalf("%v.Read(_) = _, %v: ", client, err)
+		fmt.Fprintf(os.Stderr, "failed to set up tracing: %v\n", err)
+		return int(codes.Internal)
 	}
+	defer shutdownTracing(context.Background())
 
-	log.Println("Read result: ", resp.GetTodo())
-}
-
-func readAllTodos(client pb.TodoServiceClient) {
-	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
-	defer cancel()
-
-	resp, err := client.ReadAll(ctx, &pb.ReadAllRequest{})
+	// Logs would garble the screen of interactive commands.
+	l := zap.NewNop()
+	if !cmd.interactive {
+		l = newLogger(cfg.LogLevel)
+	}
+	c, err := dial(cfg, l)
 	if err != nil {
-		log.Fatalf("%v.ReadAll(_) = _, %v: ", client, err)
+		fmt.Fprintf(os.Stderr, "failed to connect: %v\n", err)
+		return int(codes.Unavailable)
 	}
+	defer c.Close()
 
-	log.Println("ReadAll result: ", resp.GetTodos())
+	ctx := context.Background()
+	if !cmd.interactive && !cmd.transfer {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+	err = cmd.run(ctx, &env{conn: c.Conn(), client: c, out: out, timeout: cfg.Timeout}, fs.Args()[1:])
+	return exitCode(err)
 }
 
-func updateTodo(client pb.TodoServiceClient, payload *pb.Todo) {
-	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
-	defer cancel()
+// dial connects to the server configured in cfg. Commands set their own
+// timeouts, so the client has none.
+func dial(cfg config.Config, l *zap.Logger) (*client.Client, error) {
+	addr := cfg.Server
+	if addr == "" {
+		addr = fmt.Sprintf("localhost:%d", cfg.Port)
+	}
 
-	resp, err := client.Update(ctx, &pb.UpdateRequest{Todo: payload})
-	if err != nil {
-		log.Fatalf("%v.Update(_) = _, %v: ", client, err)
+	opts := []client.Option{
+		client.WithAddress(addr),
+		client.WithTimeout(0),
+		client.WithDialOptions(
+			grpc.WithUnaryInterceptor(tracing.UnaryClientInterceptor()),
+			grpc.WithStreamInterceptor(tracing.StreamClientInterceptor()),
+		),
+		client.WithLogger(l),
+	}
+	if cfg.RetryMaxAttempts > 1 {
+		opts = append(opts, client.WithRetry(client.RetryPolicy{
+			MaxAttempts:    cfg.RetryMaxAttempts,
+			InitialBackoff: cfg.RetryInitialBackoff,
+			MaxBackoff:     cfg.RetryMaxBackoff,
+			Multiplier:     2,
+		}))
+	}
+	if cfg.TLS {
+		opts = append(opts, client.WithTLS(cfg.RootCert))
+		if cfg.ClientCertFile != "" {
+			opts = append(opts, client.WithClientCert(cfg.ClientCertFile, cfg.ClientKeyFile))
+		}
+	}
+	if cfg.Token != "" {
+		opts = append(opts, client.WithToken(cfg.Token))
 	}
 
-	log.Println("Update result: ", resp.GetUpdated())
+	return client.New(opts...)
 }
 
-func deleteTodo(client pb.TodoServiceClient, todoID int64) {
-	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
-	defer cancel()
-
-	resp, err := client.Delete(ctx, &pb.DeleteRequest{Id: todoID})
-	if err != nil {
-		log.Fatalf("%v.Delete(_) = _, %v: ", client, err)
-	}
-
-	log.Println("Delete result: ", resp.GetDeleted())
+// newLogger logs to stderr, leaving stdout to the output of commands.
+// The level has been validated with the rest of the config.
+func newLogger(level string) *zap.Logger {
+	lvl, _ := logger.ParseLevel(level)
+	encoder := zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	return zap.New(zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), lvl))
 }
 
-func main() {
-	cfg := config.New()
-	creds, err := credentials.NewClientTLSFromFile(cfg.RootCert, "")
-	if err != nil {
-		log.Fatalf("failed to load credentials: %v", err)
+// exitCode prints err and maps it to the code of its gRPC status.
+// Usage errors exit with InvalidArgument.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
 	}
 
-	addr := fmt.Sprintf("localhost:%d", cfg.Port)
-	var conn *grpc.ClientConn
-	if cfg.TLS {
-		conn, err = grpc.Dial(addr, grpc.WithTransportCredentials(creds))
-	} else {
-		conn, err = grpc.Dial(addr, grpc.WithInsecure())
+	var uerr usageError
+	if errors.As(err, &uerr) {
+		fmt.Fprintf(os.Stderr, "%v\nusage: %s\n", uerr.err, uerr.cmd.usage)
+		return int(codes.InvalidArgument)
 	}
-	if err != nil {
-		log.Fatalf("failed to dial: %v", err)
+	if errors.Is(err, context.DeadlineExceeded) {
+		fmt.Fprintln(os.Stderr, "error: timed out")
+		return int(codes.DeadlineExceeded)
 	}
-	defer conn.Close()
-
-	healthClient := grpc_health_v1.NewHealthClient(conn)
-	client := pb.NewTodoServiceClient(conn)
 
-	reminder := time.Now().Add(5 * time.Second).In(time.UTC)
-	reminderProto, _ := ptypes.TimestampProto(reminder)
-	t := pb.Todo{
-		Title:       "My first grpc todo item",
-		Description: "Another first here.",
-		Reminder:    reminderProto,
-	}
-
-	checkHealth(healthClient)
-	newTodo := createTodo(client, t)
-	readTodo(client, newTodo.Id)
-	readAllTodos(client)
-	payload := &pb.Todo{Id: newTodo.Id, Title: "My updated grpc todo item"}
-	updateTodo(client, payload)
-	deleteTodo(client, newTodo.Id)
-	checkHealth(healthClient)
+	st := status.Convert(err)
+	fmt.Fprintf(os.Stderr, "error: %s\n", st.Message())
+	return int(st.Code())
 }
 
-func checkHealth(c grpc_health_v1.HealthClient) {
-	resp, err := c.Check(context.TODO(),
-		&grpc_health_v1.HealthCheckRequest{Service: "TodoService"})
-	if err != nil {
-		if s, ok := status.FromError(err); ok && s.Code() == codes.Unimplemented {
-			log.Println("the server doesn't implement the grpc health protocol")
-		} else {
-			log.Printf("gRPC health check failed: %v", err)
-		}
+func usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintf(w, "usage: client [flags] <command> [args]\n\ncommands:\n")
+	for _, name := range commandNames() {
+		c := commands[name]
+		fmt.Fprintf(w, "  %-8s %s\n", name, c.help)
 	}
-	log.Printf("Health status: %s", resp.GetStatus().String())
+	fmt.Fprintf(w, "\nflags:\n")
+	fs.PrintDefaults()
+	fmt.Fprintf(w, "\nThe exit code is the code of the gRPC status a command failed with.\n")
 }
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// outputFormats are the values of the -o flag.
+var outputFormats = []string{"table", "json", "yaml", "csv", "template"}
+
+var csvHeader = []string{"id", "title", "description", "priority", "reminder", "created_at", "updated_at"}
+
+// printer writes todos in the format chosen with -o. Times are shown in
+// the local timezone, or relative to now if relative is set, except in
+// JSON and YAML, which use the field names and RFC 3339 times of protojson.
+type printer struct {
+	w        io.Writer
+	format   string
+	tmpl     *template.Template
+	relative bool
+	now      time.Time
+}
+
+// todoView is a todo as seen by -template, with formatted times.
+type todoView struct {
+	ID          uint
+	Title       string
+	Description string
+	Priority    string
+	Reminder    string
+	CreatedAt   string
+	UpdatedAt   string
+}
+
+func newPrinter(w io.Writer, format, tmpl, timeMode string) (*printer, error) {
+	p := &printer{w: w, format: format, now: time.Now()}
+
+	switch timeMode {
+	case "local":
+	case "relative":
+		p.relative = true
+	default:
+		return nil, fmt.Errorf("-time must be local or relative, got %q", timeMode)
+	}
+
+	switch format {
+	case "table", "json", "yaml", "csv":
+	case "template":
+		if tmpl == "" {
+			return nil, fmt.Errorf("-o template requires -template")
+		}
+		t, err := template.New("todo").Parse(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %v", err)
+		}
+		p.tmpl = t
+	default:
+		return nil, fmt.Errorf("-o must be one of %s, got %q", strings.Join(outputFormats, ", "), format)
+	}
+
+	return p, nil
+}
+
+// todo prints a single todo, as an object rather than a list in
+// JSON and YAML.
+func (p *printer) todo(t todo.Todo) error {
+	switch p.format {
+	case "json":
+		b, err := marshalJSON(t)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.w, "%s\n", b)
+		return err
+	case "yaml":
+		v, err := toYAMLValue(t)
+		if err != nil {
+			return err
+		}
+		return p.writeYAML(v)
+	}
+	return p.todos([]todo.Todo{t})
+}
+
+// todos prints a list of todos.
+func (p *printer) todos(ts []todo.Todo) error {
+	switch p.format {
+	case "json":
+		items := make([]json.RawMessage, len(ts))
+		for i, t := range ts {
+			b, err := marshalJSON(t)
+			if err != nil {
+				return err
+			}
+			items[i] = b
+		}
+		b, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.w, "%s\n", b)
+		return err
+
+	case "yaml":
+		items := make([]interface{}, len(ts))
+		for i, t := range ts {
+			v, err := toYAMLValue(t)
+			if err != nil {
+				return err
+			}
+			items[i] = v
+		}
+		return p.writeYAML(items)
+
+	case "csv":
+		w := csv.NewWriter(p.w)
+		w.Write(csvHeader)
+		for _, t := range ts {
+			w.Write([]string{
+				strconv.FormatUint(uint64(t.ID), 10),
+				t.Title,
+				t.Description,
+				t.Priority,
+				p.time(t.Reminder),
+				p.time(t.CreatedAt),
+				p.time(t.UpdatedAt),
+			})
+		}
+		w.Flush()
+		return w.Error()
+
+	case "template":
+		for _, t := range ts {
+			if err := p.tmpl.Execute(p.w, p.view(t)); err != nil {
+				return err
+			}
+			fmt.Fprintln(p.w)
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTITLE\tDESCRIPTION\tREMINDER\tUPDATED")
+	for _, t := range ts {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n",
+			t.ID, t.Title, truncate(t.Description, 40), p.time(t.Reminder), p.time(t.UpdatedAt))
+	}
+	return tw.Flush()
+}
+
+func (p *printer) view(t todo.Todo) todoView {
+	return todoView{
+		ID:          t.ID,
+		Title:       t.Title,
+		Description: t.Description,
+		Priority:    t.Priority,
+		Reminder:    p.time(t.Reminder),
+		CreatedAt:   p.time(t.CreatedAt),
+		UpdatedAt:   p.time(t.UpdatedAt),
+	}
+}
+
+func (p *printer) writeYAML(v interface{}) error {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = p.w.Write(b)
+	return err
+}
+
+// time formats t, or returns an empty string if it isn't set.
+func (p *printer) time(t time.Time) string {
+	if t.Unix() <= 0 {
+		return ""
+	}
+	if p.relative {
+		return relativeTime(t, p.now)
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// relativeTime formats t as "in 2h" or "3d ago" from now.
+func relativeTime(t, now time.Time) string {
+	d := t.Sub(now)
+	future := d > 0
+	if !future {
+		d = -d
+	}
+
+	round := func(unit time.Duration) int {
+		return int((d + unit/2) / unit)
+	}
+	var s string
+	switch {
+	case d < 30*time.Second:
+		return "now"
+	case d < time.Hour-30*time.Second:
+		s = fmt.Sprintf("%dm", round(time.Minute))
+	case d < 47*time.Hour+30*time.Minute:
+		s = fmt.Sprintf("%dh", round(time.Hour))
+	default:
+		s = fmt.Sprintf("%dd", round(24*time.Hour))
+	}
+
+	if future {
+		return "in " + s
+	}
+	return s + " ago"
+}
+
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+// jsonTodo is a todo as protojson marshals it, without the fields that
+// aren't set.
+type jsonTodo struct {
+	ID          uint       `json:"id,string,omitempty"`
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Reminder    *time.Time `json:"reminder,omitempty"`
+	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
+	OwnerID     string     `json:"ownerId,omitempty"`
+	Priority    string     `json:"priority,omitempty"`
+}
+
+// marshalJSON marshals t with the protojson field names.
+func marshalJSON(t todo.Todo) ([]byte, error) {
+	utc := func(t time.Time) *time.Time {
+		if t.IsZero() {
+			return nil
+		}
+		t = t.UTC()
+		return &t
+	}
+	j := jsonTodo{
+		ID:          t.ID,
+		Title:       t.Title,
+		Description: t.Description,
+		Reminder:    utc(t.Reminder),
+		CreatedAt:   utc(t.CreatedAt),
+		UpdatedAt:   utc(t.UpdatedAt),
+		OwnerID:     t.OwnerID,
+		Priority:    t.Priority,
+	}
+	if t.DeletedAt != nil {
+		j.DeletedAt = utc(*t.DeletedAt)
+	}
+	return json.MarshalIndent(j, "", "  ")
+}
+
+// toYAMLValue converts t to a value with the same keys as its JSON.
+func toYAMLValue(t todo.Todo) (interface{}, error) {
+	b, err := marshalJSON(t)
+	if err != nil {
+		return nil, err
+	}
+	var v yaml.MapSlice
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -1,26 +1,44 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
+	"log"
 	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/dikaeinstein/prototodo/pkg/auth"
 	"github.com/dikaeinstein/prototodo/pkg/config"
+	"github.com/dikaeinstein/prototodo/pkg/healthcheck"
 	"github.com/dikaeinstein/prototodo/pkg/logger"
+	"github.com/dikaeinstein/prototodo/pkg/metrics"
 	pb "github.com/dikaeinstein/prototodo/pkg/proto"
 	g "github.com/dikaeinstein/prototodo/pkg/protocol/grpc"
 	"github.com/dikaeinstein/prototodo/pkg/protocol/grpc/interceptor"
-	"github.com/dikaeinstein/prototodo/pkg/todo"
+	"github.com/dikaeinstein/prototodo/pkg/protocol/rest"
+	"github.com/dikaeinstein/prototodo/pkg/protocol/web"
+	"github.com/dikaeinstein/prototodo/pkg/tlsutil"
 	"github.com/dikaeinstein/prototodo/pkg/todo/service"
 	"github.com/dikaeinstein/prototodo/pkg/todo/storage"
+	"github.com/dikaeinstein/prototodo/pkg/tracing"
+	"github.com/dikaeinstein/prototodo/pkg/webhook"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/postgres"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 func connectToDatabase(dbURI string, l *zap.Logger) *gorm.DB {
@@ -28,28 +46,156 @@ func connectToDatabase(dbURI string, l *zap.Logger) *gorm.DB {
 	if err != nil {
 		l.Fatal("failed to open database connection", zap.Error(err))
 	}
-	db.Debug().AutoMigrate(&todo.Todo{})
 
 	return db
 }
 
+// store keeps the todos, the idempotency keys of the calls changing them
+// and the webhooks notified of the changes.
+type store interface {
+	service.Repository
+	interceptor.IdempotencyStore
+	webhook.Store
+}
+
+// newRepository creates the todo store. The returned database is nil
+// for the in-memory store.
+func newRepository(cfg config.Config, l *zap.Logger, reg prometheus.Registerer) (store, *gorm.DB) {
+	if cfg.Store == "memory" {
+		l.Warn("using in-memory store, todos will be lost on restart")
+		return storage.NewMemoryStore(), nil
+	}
+
+	dbURI := fmt.Sprintf("host=localhost user=Dikaeinstein dbname=%s sslmode=disable", cfg.DBName)
+	db := connectToDatabase(dbURI, l)
+	if err := metrics.InstrumentDB(reg, db); err != nil {
+		l.Fatal("failed to register database metrics", zap.Error(err))
+	}
+	tracing.InstrumentDB(db)
+	// SQL statements, without their values, are logged at debug level
+	// by the storage logger.
+	db.SetLogger(storage.GormLogger{L: l.Named("storage")})
+	db.LogMode(true)
+	p := storage.NewPostgresStore(db)
+	if err := p.Migrate(); err != nil {
+		l.Fatal("failed to migrate database", zap.Error(err))
+	}
+
+	return p, db
+}
+
+// newGRPCServer creates a gRPC server serving the todo and health services.
+func newGRPCServer(srv pb.TodoServiceServer, h *health.Server, m *grpc_prometheus.ServerMetrics, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	pb.RegisterTodoServiceServer(s, srv)
+	grpc_health_v1.RegisterHealthServer(s, h)
+	m.InitializeMetrics(s)
+
+	return s
+}
+
+// dialInProcess connects to the gRPC server listening on lis.
+func dialInProcess(lis *bufconn.Listener) (*grpc.ClientConn, error) {
+	return grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithInsecure(),
+	)
+}
+
+// startHTTP serves h on addr in the background, over TLS if tlsConfig
+// isn't nil.
+func startHTTP(name string, h http.Handler, addr string, tlsConfig *tls.Config, l *zap.Logger) *http.Server {
+	s := &http.Server{
+		Addr:      addr,
+		Handler:   h,
+		TLSConfig: tlsConfig,
+	}
+
+	go func() {
+		l.Info(fmt.Sprintf("%s listening on %s...", name, addr))
+		var err error
+		if tlsConfig != nil {
+			err = s.ListenAndServeTLS("", "")
+		} else {
+			err = s.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			l.Error("failed to serve "+name, zap.Error(err))
+		}
+	}()
+
+	return s
+}
+
 func main() {
-	cfg := config.New()
+	configFile := config.FileFromArgs(os.Args[1:])
+	cfg, loadErr := config.Load(configFile)
+	flag.String("config", configFile, "The YAML or TOML config file, also read from $"+config.FileEnv)
 	flag.BoolVar(&cfg.TLS, "tls", cfg.TLS, "Connection uses TLS if true, else plain TCP")
 	flag.StringVar(&cfg.DBName, "db_name", cfg.DBName, "The database name")
+	flag.StringVar(&cfg.Store, "store", cfg.Store, "The todo store, postgres or memory")
 	flag.IntVar(&cfg.Port, "port", cfg.Port, "The server port")
+	flag.IntVar(&cfg.GatewayPort, "gateway_port", cfg.GatewayPort, "The REST gateway port, 0 disables the gateway")
+	flag.IntVar(&cfg.GRPCWebPort, "grpc_web_port", cfg.GRPCWebPort, "The grpc-web port, 0 disables grpc-web")
+	flag.StringVar(&cfg.CORSAllowedOrigins, "cors_allowed_origins", cfg.CORSAllowedOrigins, "Comma separated origins allowed to call grpc-web, * allows any")
+	flag.BoolVar(&cfg.Reflection, "reflection", cfg.Reflection, "Register the gRPC server reflection service")
+	flag.IntVar(&cfg.MetricsPort, "metrics_port", cfg.MetricsPort, "The prometheus metrics port, 0 disables metrics")
+	flag.IntVar(&cfg.HealthPort, "health_port", cfg.HealthPort, "The port of the /healthz and /readyz probes, 0 serves them on the metrics port")
+	flag.IntVar(&cfg.AdminPort, "admin_port", cfg.AdminPort, "The localhost port of the /loglevel endpoint, 0 disables it")
+	flag.DurationVar(&cfg.HealthCheckInterval, "health_check_interval", cfg.HealthCheckInterval, "How often the database and other dependencies are checked")
+	flag.DurationVar(&cfg.HealthCheckTimeout, "health_check_timeout", cfg.HealthCheckTimeout, "How long a dependency check may take")
+	flag.StringVar(&cfg.OTLPEndpoint, "otlp_endpoint", cfg.OTLPEndpoint, "The OpenTelemetry collector URL, spans go to stderr if empty")
+	flag.DurationVar(&cfg.ShutdownDrainPeriod, "shutdown_drain_period", cfg.ShutdownDrainPeriod, "How long health checks fail before the servers stop on shutdown")
+	flag.DurationVar(&cfg.ShutdownTimeout, "shutdown_timeout", cfg.ShutdownTimeout, "How long in-flight requests may run after the drain period")
 	flag.StringVar(&cfg.AppEnv, "app_env", cfg.AppEnv, "The app environment")
-	flag.IntVar(&cfg.LogLevel, "log_level", cfg.LogLevel, "Global log level")
+	flag.StringVar(&cfg.LogLevel, "log_level", cfg.LogLevel, "Global log level: debug, info, warn or error")
+	flag.StringVar(&cfg.LogFormat, "log_format", cfg.LogFormat, "Log format: console, json or logfmt")
+	flag.StringVar(&cfg.LogFile, "log_file", cfg.LogFile, "Write logs to this file, rotated by size, instead of stdout and stderr")
 	flag.StringVar(&cfg.CertFile, "cert_file", cfg.CertFile, "The TLS cert file")
 	flag.StringVar(&cfg.KeyFile, "key_file", cfg.KeyFile, "The TLS key file")
+	flag.StringVar(&cfg.ClientCAFile, "client_ca_file", cfg.ClientCAFile, "The CA bundle client certificates are verified against")
+	flag.StringVar(&cfg.ClientAuth, "client_auth", cfg.ClientAuth, "Client certificate verification: none, optional or required")
+	flag.DurationVar(&cfg.CertReloadInterval, "cert_reload_interval", cfg.CertReloadInterval, "How often TLS files are checked for changes")
+	flag.StringVar(&cfg.JWTHMACKeyFile, "jwt_hmac_key_file", cfg.JWTHMACKeyFile, "The HS256 JWT secret file")
+	flag.StringVar(&cfg.JWTRSAPublicKeyFile, "jwt_rsa_public_key_file", cfg.JWTRSAPublicKeyFile, "The RS256 JWT public key file")
+	flag.StringVar(&cfg.JWTAudience, "jwt_audience", cfg.JWTAudience, "The audience JWTs must be issued for")
+	flag.StringVar(&cfg.PolicyFile, "policy_file", cfg.PolicyFile, "The role-based authorization policy file, reloaded on SIGHUP")
+	flag.StringVar(&cfg.RateLimits, "rate_limits", cfg.RateLimits, "Per caller rate limits as method=rate:burst pairs, e.g. *=10:20")
+	flag.DurationVar(&cfg.IdempotencyKeyTTL, "idempotency_key_ttl", cfg.IdempotencyKeyTTL, "How long the responses to calls with an idempotency key are replayed to retries")
+	flag.IntVar(&cfg.WebhookMaxAttempts, "webhook_max_attempts", cfg.WebhookMaxAttempts, "How many times a webhook delivery is attempted before it is dead-lettered")
+	flag.DurationVar(&cfg.WebhookTimeout, "webhook_timeout", cfg.WebhookTimeout, "How long a webhook delivery attempt may take")
 
 	flag.Parse()
 
-	zapLogger := logger.NewZapLogger(cfg.LogLevel, "2006-01-02T15:04:05Z07:00", cfg.AppEnv)
+	if err := config.Join(loadErr, cfg.Validate()); err != nil {
+		log.Fatal(err)
+	}
+	flagged := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		flagged[f.Name] = true
+	})
+
+	// The level is valid, cfg has been validated.
+	level, _ := logger.ParseLevel(cfg.LogLevel)
+	levels := logger.NewLevels(level)
+	zapLogger, err := logger.NewZapLogger(levels, cfg.AppEnv, cfg.LoggerOptions())
+	if err != nil {
+		log.Fatalf("failed to create logger: %v", err)
+	}
+	// RPCs are logged by the grpc logger, which the loggers of the
+	// layers below are named after.
+	grpcLogger := zapLogger.Named("grpc")
 	defer zapLogger.Sync()
 
-	dbURI := fmt.Sprintf("host=localhost user=Dikaeinstein dbname=%s sslmode=disable", cfg.DBName)
-	db := connectToDatabase(dbURI, zapLogger)
+	// Without a collector spans go to stderr, keeping them out of the logs
+	// on stdout.
+	shutdownTracing, err := tracing.Init(context.Background(), "prototodo-server", cfg.OTLPEndpoint, os.Stderr)
+	if err != nil {
+		zapLogger.Fatal("failed to set up tracing", zap.Error(err))
+	}
+	defer shutdownTracing(context.Background())
 
 	lis, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", cfg.Port))
 	if err != nil {
@@ -57,35 +203,243 @@ func main() {
 	}
 	defer lis.Close()
 
-	r := storage.NewPostgresStore(db)
-	s := service.New(r)
+	reg := metrics.NewRegistry()
+	serverMetrics := metrics.NewServerMetrics(reg)
+
+	r, db := newRepository(cfg, zapLogger, reg)
+	webhooks := webhook.NewDispatcher(r, cfg.WebhookMaxAttempts, cfg.WebhookTimeout, zapLogger.Named("webhook"))
+	s := service.New(r, webhooks)
 	srv := g.NewGRPCTodoHandler(s)
 
-	var opts []grpc.ServerOption
+	// The background workers run until the servers are stopped.
+	bg := newWorkers()
+	bg.start(webhooks.Run)
+	bg.start(service.NewReminders(r, webhooks, zapLogger.Named("reminders")).Run)
+	shutdown := notifyShutdown()
+
+	var creds []grpc.ServerOption
+	var gatewayTLS, webTLS *tls.Config
+	clientAuth, err := tlsutil.ParseClientAuth(cfg.ClientAuth)
+	if err != nil {
+		zapLogger.Fatal("invalid client auth mode", zap.Error(err))
+	}
 	if cfg.TLS {
-		creds, err := credentials.NewServerTLSFromFile(cfg.CertFile, cfg.KeyFile)
+		reloader, err := tlsutil.NewReloader(cfg.CertFile, cfg.KeyFile, cfg.ClientCAFile, zapLogger)
 		if err != nil {
 			zapLogger.Fatal("Failed to generate credentials", zap.Error(err))
 		}
-		opts = []grpc.ServerOption{grpc.Creds(creds)}
+		serverTLS, err := reloader.ServerConfig(clientAuth)
+		if err != nil {
+			zapLogger.Fatal("invalid client auth", zap.Error(err))
+		}
+		// Configs without client auth can't fail.
+		gatewayTLS, _ = reloader.HTTPServerConfig(tls.NoClientCert)
+		webTLS, err = reloader.HTTPServerConfig(clientAuth)
+		if err != nil {
+			zapLogger.Fatal("invalid client auth", zap.Error(err))
+		}
+		bg.start(func(stop <-chan struct{}) { reloader.Run(cfg.CertReloadInterval, stop) })
+		creds = []grpc.ServerOption{grpc.Creds(credentials.NewTLS(serverTLS))}
 	}
 
-	opts = append(opts, grpc_middleware.WithUnaryServerChain(
-		interceptor.LogRPCCalls(zapLogger),
-	))
+	unary := []grpc.UnaryServerInterceptor{
+		interceptor.LogRPCCalls(grpcLogger),
+		interceptor.RequestIDUnary(),
+		interceptor.TraceUnary(),
+		serverMetrics.UnaryServerInterceptor(),
+		interceptor.RecoverUnary(),
+	}
+	stream := []grpc.StreamServerInterceptor{
+		interceptor.LogStreamRPCCalls(grpcLogger),
+		interceptor.RequestIDStream(),
+		interceptor.TraceStream(),
+		serverMetrics.StreamServerInterceptor(),
+		interceptor.RecoverStream(),
+	}
+	var v interceptor.TokenValidator
+	if cfg.JWTHMACKeyFile != "" || cfg.JWTRSAPublicKeyFile != "" {
+		v, err = auth.NewJWTValidator(cfg.JWTHMACKeyFile, cfg.JWTRSAPublicKeyFile, cfg.JWTAudience)
+		if err != nil {
+			zapLogger.Fatal("failed to load JWT keys", zap.Error(err))
+		}
+	}
+	authenticated := v != nil || (cfg.TLS && clientAuth != tls.NoClientCert)
+	if authenticated {
+		unary = append(unary, interceptor.AuthenticateUnary(v))
+		stream = append(stream, interceptor.AuthenticateStream(v))
+	} else {
+		zapLogger.Warn("no authentication configured, all callers share the anonymous user")
+	}
+	// The rate limiter is always installed, so limits can be
+	// added by reloading the config.
+	limits, err := interceptor.ParseLimits(cfg.RateLimits)
+	if err != nil {
+		zapLogger.Fatal("invalid rate limits", zap.Error(err))
+	}
+	rl := interceptor.NewRateLimiter(limits)
+	unary = append(unary, interceptor.RateLimitUnary(rl))
+	// Each imported todo takes a token of Create.
+	stream = append(stream, interceptor.RateLimitStream(rl, map[string]string{
+		"/todo.v1.TodoService/Import": "/todo.v1.TodoService/Create",
+	}))
+
+	var policy *auth.Policy
+	if cfg.PolicyFile != "" {
+		policy, err = auth.LoadPolicy(cfg.PolicyFile)
+		if err != nil {
+			zapLogger.Fatal("failed to load authorization policy", zap.Error(err))
+		}
+		unary = append(unary, interceptor.AuthorizeUnary(policy, grpcLogger))
+		stream = append(stream, interceptor.AuthorizeStream(policy, grpcLogger))
+	}
+	// Idempotency keys are checked last, so that calls rejected by the
+	// interceptors before don't use up their key.
+	idempotency := interceptor.NewIdempotency(r, cfg.IdempotencyKeyTTL, zapLogger.Named("idempotency"))
+	bg.start(idempotency.Run)
+	unary = append(unary, interceptor.IdempotencyUnary(idempotency))
+
+	hr := &hotReloader{
+		configFile: configFile,
+		flagged:    flagged,
+		cfg:        cfg,
+		levels:     levels,
+		limiter:    rl,
+		policy:     policy,
+		l:          zapLogger,
+	}
+	bg.start(hr.run)
 
-	grpcServer := grpc.NewServer(opts...)
-	pb.RegisterTodoServiceServer(grpcServer, srv)
 	h := health.NewServer()
-	grpc_health_v1.RegisterHealthServer(grpcServer, h)
-	h.SetServingStatus("TodoService", grpc_health_v1.HealthCheckResponse_SERVING)
+	checker := healthcheck.New(h, "TodoService", cfg.HealthCheckInterval, cfg.HealthCheckTimeout, zapLogger.Named("health"))
+	if db != nil {
+		checker.Add("database", func(ctx context.Context) error {
+			return db.DB().PingContext(ctx)
+		})
+	}
+	bg.start(checker.Run)
+	grpcServer := newGRPCServer(srv, h, serverMetrics, append(creds,
+		grpc_middleware.WithUnaryServerChain(unary...),
+		grpc_middleware.WithStreamServerChain(stream...),
+	)...)
+
+	// Anyone could change the log levels without authorization, so the
+	// admin service is only served with a policy granting it.
+	if authenticated && policy != nil {
+		pb.RegisterAdminServiceServer(grpcServer, g.NewGRPCAdminHandler(levels))
+	} else {
+		zapLogger.Warn("admin service disabled, it needs authentication and an authorization policy")
+	}
+	pb.RegisterWebhookServiceServer(grpcServer, g.NewGRPCWebhookHandler(webhooks))
+
+	if cfg.Reflection {
+		reflection.Register(grpcServer)
+	}
+
+	// HTTP servers are stopped in order before the gRPC servers, so
+	// in-flight REST and grpc-web calls can still reach them. The metrics
+	// and admin servers are stopped after the gRPC servers, so the shutdown
+	// can be watched until the end.
+	var httpServers, opsServers []namedServer
+	startHTTPServer := func(name string, h http.Handler, addr string, tlsConfig *tls.Config) namedServer {
+		return namedServer{name, startHTTP(name, h, addr, tlsConfig, zapLogger)}
+	}
+
+	if cfg.GRPCWebPort != 0 {
+		// grpc-web calls are served by grpcServer itself, so browsers go
+		// through the same interceptors and may present client certificates.
+		origins := strings.Split(cfg.CORSAllowedOrigins, ",")
+		httpServers = append(httpServers, startHTTPServer("grpc-web server", web.NewHandler(grpcServer, origins), fmt.Sprintf(":%d", cfg.GRPCWebPort), webTLS))
+	}
+
+	var gatewayServer *grpc.Server
+	var gatewayConn *grpc.ClientConn
+	if cfg.GatewayPort != 0 {
+		// The gateway calls an in-process server without TLS that runs the
+		// same interceptors, so REST callers are authenticated, limited and
+		// authorized like gRPC callers.
+		gatewayLis := bufconn.Listen(1 << 20)
+		gatewayServer = newGRPCServer(srv, h, serverMetrics,
+			grpc_middleware.WithUnaryServerChain(append([]grpc.UnaryServerInterceptor{interceptor.ForwardedPeerUnary()}, unary...)...),
+			grpc_middleware.WithStreamServerChain(append([]grpc.StreamServerInterceptor{interceptor.ForwardedPeerStream()}, stream...)...),
+		)
+		go gatewayServer.Serve(gatewayLis)
 
+		gatewayConn, err = dialInProcess(gatewayLis)
+		if err != nil {
+			zapLogger.Fatal("failed to connect REST gateway", zap.Error(err))
+		}
+		gw, err := rest.NewGateway(context.Background(), gatewayConn)
+		if err != nil {
+			zapLogger.Fatal("failed to create REST gateway", zap.Error(err))
+		}
+		httpServers = append(httpServers, startHTTPServer("REST gateway", gw, fmt.Sprintf(":%d", cfg.GatewayPort), gatewayTLS))
+	}
+
+	probes := http.NewServeMux()
+	probes.HandleFunc("/healthz", checker.Healthz)
+	probes.HandleFunc("/readyz", checker.Readyz)
+	if cfg.HealthPort != 0 {
+		opsServers = append(opsServers, startHTTPServer("health server", probes, fmt.Sprintf(":%d", cfg.HealthPort), nil))
+	}
+	if cfg.MetricsPort != 0 {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler(reg))
+		if cfg.HealthPort == 0 {
+			mux.Handle("/healthz", probes)
+			mux.Handle("/readyz", probes)
+		}
+		opsServers = append(opsServers, startHTTPServer("metrics server", mux, fmt.Sprintf(":%d", cfg.MetricsPort), nil))
+	}
+	// /loglevel has no authentication, so it is only served to localhost.
+	if cfg.AdminPort != 0 {
+		mux := http.NewServeMux()
+		mux.Handle("/loglevel", levels)
+		opsServers = append(opsServers, startHTTPServer("admin server", mux, fmt.Sprintf("localhost:%d", cfg.AdminPort), nil))
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- grpcServer.Serve(lis)
+	}()
 	msg := fmt.Sprintf("gRPC server listening on %d...", cfg.Port)
 	zapLogger.Info(msg)
-	if err := grpcServer.Serve(lis); err != nil {
+
+	select {
+	case err := <-serveErr:
 		zapLogger.Fatal("failed to serve", zap.Error(err))
+	case sig := <-shutdown:
+		zapLogger.Info("shutting down", zap.Stringer("signal", sig))
+	}
+
+	// Fail health checks first and give load balancers the drain period
+	// to stop sending new requests before the listeners are closed.
+	h.Shutdown()
+	zapLogger.Info("draining connections", zap.Duration("drain_period", cfg.ShutdownDrainPeriod))
+	time.Sleep(cfg.ShutdownDrainPeriod)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	for _, s := range httpServers {
+		stopHTTP(ctx, s.name, s.Server, zapLogger)
+	}
+	if gatewayServer != nil {
+		gatewayConn.Close()
+		stopGRPC(ctx, "REST gateway gRPC server", gatewayServer, zapLogger)
+	}
+	stopGRPC(ctx, "gRPC server", grpcServer, zapLogger)
+	for _, s := range opsServers {
+		stopHTTP(ctx, s.name, s.Server, zapLogger)
 	}
 
-	h.SetServingStatus("TodoService", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
-	grpcServer.GracefulStop()
+	// The workers may be using the database, such as a webhook delivering
+	// a batch, so it is closed once they have returned.
+	if err := bg.stop(ctx); err != nil {
+		zapLogger.Warn("background workers did not stop in time", zap.Error(err))
+	}
+	if db != nil {
+		if err := db.Close(); err != nil {
+			zapLogger.Error("failed to close database", zap.Error(err))
+		}
+	}
+	zapLogger.Info("server stopped")
 }
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/dikaeinstein/prototodo/pkg/auth"
+	"github.com/dikaeinstein/prototodo/pkg/config"
+	"github.com/dikaeinstein/prototodo/pkg/logger"
+	"github.com/dikaeinstein/prototodo/pkg/protocol/grpc/interceptor"
+	"go.uber.org/zap"
+)
+
+// hotReloader applies the settings that can be changed without a
+// restart every time the process receives SIGHUP: the log level, the
+// rate limits and the authorization policy.
+type hotReloader struct {
+	configFile string
+	// flagged holds the flags given on the command line, whose values
+	// keep precedence over the reloaded config.
+	flagged map[string]bool
+	cfg     config.Config
+
+	levels  *logger.Levels
+	limiter *interceptor.RateLimiter
+	policy  *auth.Policy
+	l       *zap.Logger
+}
+
+// run reloads on SIGHUP until stop is closed.
+func (r *hotReloader) run(stop <-chan struct{}) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-hup:
+			r.reload()
+		}
+	}
+}
+
+func (r *hotReloader) reload() {
+	if r.policy != nil {
+		if err := r.policy.Reload(); err != nil {
+			r.l.Error("failed to reload authorization policy", zap.Error(err))
+		} else {
+			r.l.Info("reloaded authorization policy")
+		}
+	}
+
+	next, err := config.Load(r.configFile)
+	if err == nil {
+		err = next.Validate()
+	}
+	if err != nil {
+		r.l.Error("failed to reload config, keeping the current one", zap.Error(err))
+		return
+	}
+	if r.flagged["log_level"] {
+		next.LogLevel = r.cfg.LogLevel
+	}
+	if r.flagged["rate_limits"] {
+		next.RateLimits = r.cfg.RateLimits
+	}
+	limits, err := interceptor.ParseLimits(next.RateLimits)
+	if err != nil {
+		r.l.Error("failed to reload config, keeping the current one", zap.Error(err))
+		return
+	}
+
+	if next.LogLevel != r.cfg.LogLevel {
+		// The level is valid, next has been validated.
+		level, _ := logger.ParseLevel(next.LogLevel)
+		r.levels.Set(logger.Root, level, 0)
+		r.l.Info("changed log level", zap.Stringer("level", level))
+	}
+	if next.RateLimits != r.cfg.RateLimits {
+		r.limiter.SetLimits(limits)
+		r.l.Info("changed rate limits", zap.String("rate_limits", next.RateLimits))
+	}
+	r.cfg.LogLevel = next.LogLevel
+	r.cfg.RateLimits = next.RateLimits
+}
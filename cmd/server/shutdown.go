@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// notifyShutdown returns a channel that receives the first SIGINT or
+// SIGTERM. Later signals are no longer caught, so a second one kills
+// the process without waiting for the shutdown to finish.
+func notifyShutdown() <-chan os.Signal {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+
+	first := make(chan os.Signal, 1)
+	go func() {
+		s := <-sig
+		signal.Stop(sig)
+		first <- s
+	}()
+
+	return first
+}
+
+// namedServer is an HTTP server and the name it is logged under.
+type namedServer struct {
+	name string
+	*http.Server
+}
+
+// stopHTTP waits for the in-flight requests of s to finish and closes
+// the connections still open when ctx is done.
+func stopHTTP(ctx context.Context, name string, s *http.Server, l *zap.Logger) {
+	if err := s.Shutdown(ctx); err != nil {
+		l.Warn(name+" did not stop in time, closing connections", zap.Error(err))
+		s.Close()
+	}
+}
+
+// stopGRPC waits for the in-flight RPCs of s to finish and cancels
+// the ones still running when ctx is done.
+func stopGRPC(ctx context.Context, name string, s *grpc.Server, l *zap.Logger) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		l.Warn(name+" did not stop in time, cancelling RPCs", zap.Error(ctx.Err()))
+		s.Stop()
+	}
+}
+
+// workers runs background workers until they are stopped.
+type workers struct {
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newWorkers() *workers {
+	return &workers{done: make(chan struct{})}
+}
+
+// start runs run in a goroutine, passing it the channel that is closed
+// when the workers are stopped.
+func (w *workers) start(run func(stop <-chan struct{})) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		run(w.done)
+	}()
+}
+
+// stop tells the workers to stop and waits for them to return, giving up
+// when ctx is done.
+func (w *workers) stop(ctx context.Context) error {
+	close(w.done)
+
+	returned := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(returned)
+	}()
+
+	select {
+	case <-returned:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,29 @@
+package auth
+
+import "context"
+
+// Principal is the authenticated caller of an RPC.
+type Principal struct {
+	// Subject uniquely identifies the caller and owns the todos it creates.
+	Subject string
+	Roles   []string
+}
+
+// Anonymous is the principal of callers that didn't authenticate.
+var Anonymous = Principal{}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx that carries the principal p.
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx, or Anonymous
+// if the caller didn't authenticate.
+func FromContext(ctx context.Context) Principal {
+	if p, ok := ctx.Value(principalKey{}).(Principal); ok {
+		return p
+	}
+	return Anonymous
+}
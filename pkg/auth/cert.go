@@ -0,0 +1,23 @@
+package auth
+
+import "crypto/x509"
+
+// FromCertificate maps a verified client certificate to a principal.
+// The subject is the first URI SAN, e.g. a SPIFFE ID, falling back to
+// the first DNS or email SAN and then the subject common name. The
+// organizational units of the subject are used as roles.
+func FromCertificate(cert *x509.Certificate) Principal {
+	p := Principal{Roles: cert.Subject.OrganizationalUnit}
+	switch {
+	case len(cert.URIs) > 0:
+		p.Subject = cert.URIs[0].String()
+	case len(cert.DNSNames) > 0:
+		p.Subject = cert.DNSNames[0]
+	case len(cert.EmailAddresses) > 0:
+		p.Subject = cert.EmailAddresses[0]
+	default:
+		p.Subject = cert.Subject.CommonName
+	}
+
+	return p
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// leeway is the clock skew tolerated when checking exp and nbf.
+const leeway = 30 * time.Second
+
+var (
+	errMissingExpiry  = errors.New("token has no exp claim")
+	errMissingSubject = errors.New("token has no sub claim")
+	errBadAudience    = errors.New("token audience doesn't match")
+)
+
+// JWTValidator validates JWT bearer tokens signed with HS256 or RS256.
+type JWTValidator struct {
+	hmacKey  []byte
+	rsaKey   *rsa.PublicKey
+	audience string
+}
+
+// NewJWTValidator creates a JWTValidator with the keys read from hmacKeyFile
+// and rsaPublicKeyFile. Either file may be empty to disable that algorithm.
+// If audience is not empty, tokens must list it in their aud claim.
+func NewJWTValidator(hmacKeyFile, rsaPublicKeyFile, audience string) (*JWTValidator, error) {
+	if hmacKeyFile == "" && rsaPublicKeyFile == "" {
+		return nil, errors.New("no JWT verification key configured")
+	}
+
+	v := &JWTValidator{audience: audience}
+	if hmacKeyFile != "" {
+		key, err := ioutil.ReadFile(hmacKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read HMAC key: %v", err)
+		}
+		v.hmacKey = key
+	}
+	if rsaPublicKeyFile != "" {
+		pem, err := ioutil.ReadFile(rsaPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read RSA public key: %v", err)
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RSA public key: %v", err)
+		}
+		v.rsaKey = key
+	}
+
+	return v, nil
+}
+
+// Validate verifies the signature, expiry, not-before time and audience
+// of token and returns the principal it identifies.
+func (v *JWTValidator) Validate(token string) (Principal, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, v.key)
+	if err != nil {
+		return Anonymous, err
+	}
+
+	if c.Subject == "" {
+		return Anonymous, errMissingSubject
+	}
+	if v.audience != "" && !c.Audience.contains(v.audience) {
+		return Anonymous, errBadAudience
+	}
+
+	return Principal{Subject: c.Subject, Roles: c.Roles}, nil
+}
+
+// key picks the verification key for the signing method of t, rejecting
+// algorithms we don't have a key for so an RS256 public key can't be
+// used as an HS256 secret.
+func (v *JWTValidator) key(t *jwt.Token) (interface{}, error) {
+	switch t.Method {
+	case jwt.SigningMethodHS256:
+		if v.hmacKey != nil {
+			return v.hmacKey, nil
+		}
+	case jwt.SigningMethodRS256:
+		if v.rsaKey != nil {
+			return v.rsaKey, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+}
+
+type claims struct {
+	Subject   string   `json:"sub"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	Roles     []string `json:"roles"`
+}
+
+// Valid implements jwt.Claims.
+func (c *claims) Valid() error {
+	now := time.Now()
+	if c.ExpiresAt == 0 {
+		return errMissingExpiry
+	}
+	if now.Add(-leeway).After(time.Unix(c.ExpiresAt, 0)) {
+		return errors.New("token is expired")
+	}
+	if c.NotBefore != 0 && now.Add(leeway).Before(time.Unix(c.NotBefore, 0)) {
+		return errors.New("token is not valid yet")
+	}
+	return nil
+}
+
+// audience is the aud claim, which may be a single string or a list.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = audience{s}
+		return nil
+	}
+
+	var ss []string
+	if err := json.Unmarshal(b, &ss); err != nil {
+		return err
+	}
+	*a = ss
+	return nil
+}
+
+func (a audience) contains(aud string) bool {
+	for _, s := range a {
+		if s == aud {
+			return true
+		}
+	}
+	return false
+}
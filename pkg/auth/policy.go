@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Policy grants roles access to gRPC methods. It is loaded from a YAML file:
+//
+//	default_roles: [user]
+//	roles:
+//	  user:
+//	    - /todo.v1.TodoService/Read
+//	    - /todo.v1.TodoService/ReadAll
+//	  admin:
+//	    - /todo.v1.TodoService/*
+//
+// A method ending in /* grants every method of that service. Default roles
+// are granted to every authenticated principal.
+type Policy struct {
+	path string
+
+	mu    sync.RWMutex
+	rules policyFile
+}
+
+type policyFile struct {
+	DefaultRoles []string            `yaml:"default_roles"`
+	Roles        map[string][]string `yaml:"roles"`
+}
+
+// LoadPolicy reads the policy in the YAML file at path.
+func LoadPolicy(path string) (*Policy, error) {
+	p := &Policy{path: path}
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Reload reads the policy file again. The current policy is kept
+// if the file can't be loaded.
+func (p *Policy) Reload() error {
+	b, err := ioutil.ReadFile(p.path)
+	if err != nil {
+		return fmt.Errorf("failed to read policy: %v", err)
+	}
+
+	var rules policyFile
+	if err := yaml.UnmarshalStrict(b, &rules); err != nil {
+		return fmt.Errorf("failed to parse policy: %v", err)
+	}
+	for role, methods := range rules.Roles {
+		for _, m := range methods {
+			if !strings.HasPrefix(m, "/") {
+				return fmt.Errorf("role %s: method %q is not a full method name", role, m)
+			}
+		}
+	}
+
+	p.mu.Lock()
+	p.rules = rules
+	p.mu.Unlock()
+
+	return nil
+}
+
+// Allowed reports whether any role of the principal grants access
+// to the full gRPC method name, e.g. /todo.v1.TodoService/Delete.
+func (p *Policy) Allowed(pr Principal, fullMethod string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, roles := range [][]string{p.rules.DefaultRoles, pr.Roles} {
+		for _, role := range roles {
+			for _, m := range p.rules.Roles[role] {
+				if matchMethod(m, fullMethod) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func matchMethod(pattern, fullMethod string) bool {
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(fullMethod, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == fullMethod
+}
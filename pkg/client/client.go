@@ -0,0 +1,312 @@
+// Package client is a Go SDK for the todo service. It dials the server,
+// sets timeouts, retries and credentials, and converts between the
+// protos of the API and todo.Todo and time.Time values.
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/dikaeinstein/prototodo/pkg/proto"
+	"github.com/dikaeinstein/prototodo/pkg/tlsutil"
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+	"github.com/golang/protobuf/ptypes"
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+)
+
+// Client calls the todo service. Its methods return an *Error when the
+// server fails a call.
+type Client struct {
+	conn    *grpc.ClientConn
+	todos   pb.TodoServiceClient
+	timeout time.Duration
+	ownConn bool
+}
+
+// New connects to the server as configured by opts.
+func New(opts ...Option) (*Client, error) {
+	o := options{address: DefaultAddress, timeout: DefaultTimeout, l: zap.NewNop()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	c := &Client{conn: o.conn, timeout: o.timeout}
+	if c.conn == nil {
+		conn, err := dial(o)
+		if err != nil {
+			return nil, err
+		}
+		c.conn = conn
+		c.ownConn = true
+	}
+	c.todos = pb.NewTodoServiceClient(c.conn)
+
+	return c, nil
+}
+
+func dial(o options) (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{}
+	if o.tls {
+		creds, err := transportCredentials(o)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load credentials: %v", err)
+		}
+		opts = append(opts, grpc.WithTransportCredentials(creds))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	if o.token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenAuth{o.token, o.tls}))
+	}
+	if o.retry != nil {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(o.retry.unaryInterceptor(o.l)))
+	}
+	opts = append(opts, o.dialOptions...)
+
+	return grpc.Dial(o.address, opts...)
+}
+
+// transportCredentials trusts the server certificate signed by rootCert,
+// presenting the client certificate if one is configured.
+func transportCredentials(o options) (credentials.TransportCredentials, error) {
+	if o.certFile == "" {
+		return credentials.NewClientTLSFromFile(o.rootCert, "")
+	}
+
+	r, err := tlsutil.NewReloader(o.certFile, o.keyFile, o.rootCert, zap.NewNop())
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(r.ClientConfig()), nil
+}
+
+// tokenAuth sends a bearer token with every call.
+type tokenAuth struct {
+	token  string
+	secure bool
+}
+
+func (t tokenAuth) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+func (t tokenAuth) RequireTransportSecurity() bool {
+	return t.secure
+}
+
+// Close closes the connection, unless it was passed in with WithConn.
+func (c *Client) Close() error {
+	if !c.ownConn {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// Conn returns the connection, for calling other services of the server.
+func (c *Client) Conn() *grpc.ClientConn {
+	return c.conn
+}
+
+// context applies the default timeout unless ctx already has a deadline.
+func (c *Client) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || c.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// Create creates a todo with the title, description, priority and
+// reminder of t. Like Update and Delete, it is sent with an idempotency
+// key, so retrying it doesn't create the todo twice.
+func (c *Client) Create(ctx context.Context, t todo.Todo) (todo.Todo, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+
+	tProto, err := toProto("create", t)
+	if err != nil {
+		return todo.Todo{}, err
+	}
+	resp, err := c.todos.Create(ctx, &pb.CreateRequest{Todo: tProto, IdempotencyKey: NewIdempotencyKey()})
+	if err != nil {
+		return todo.Todo{}, wrapError("create", err)
+	}
+	return fromProto(resp.Todo)
+}
+
+// Read returns the todo with id.
+func (c *Client) Read(ctx context.Context, id uint) (todo.Todo, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+
+	resp, err := c.todos.Read(ctx, &pb.ReadRequest{Id: int64(id)})
+	if err != nil {
+		return todo.Todo{}, wrapError("read", err)
+	}
+	return fromProto(resp.Todo)
+}
+
+// Update sets the title, description, priority and reminder of the todo
+// with id to those of t, leaving the ones that are empty in t unchanged.
+func (c *Client) Update(ctx context.Context, id uint, t todo.Todo) (todo.Todo, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+
+	tProto, err := toProto("update", t)
+	if err != nil {
+		return todo.Todo{}, err
+	}
+	tProto.Id = int64(id)
+	resp, err := c.todos.Update(ctx, &pb.UpdateRequest{Todo: tProto, IdempotencyKey: NewIdempotencyKey()})
+	if err != nil {
+		return todo.Todo{}, wrapError("update", err)
+	}
+	return fromProto(resp.Updated)
+}
+
+// Delete deletes the todo with id.
+func (c *Client) Delete(ctx context.Context, id uint) error {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+
+	if _, err := c.todos.Delete(ctx, &pb.DeleteRequest{Id: int64(id), IdempotencyKey: NewIdempotencyKey()}); err != nil {
+		return wrapError("delete", err)
+	}
+	return nil
+}
+
+// List returns an iterator over all the caller's todos.
+func (c *Client) List(ctx context.Context) *Iterator {
+	return newIterator(func(string) ([]todo.Todo, string, error) {
+		ctx, cancel := c.context(ctx)
+		defer cancel()
+
+		resp, err := c.todos.ReadAll(ctx, &pb.ReadAllRequest{})
+		if err != nil {
+			return nil, "", wrapError("list", err)
+		}
+		tt := make([]todo.Todo, len(resp.Todos))
+		for i, tProto := range resp.Todos {
+			if tt[i], err = fromProto(tProto); err != nil {
+				return nil, "", err
+			}
+		}
+		return tt, "", nil
+	})
+}
+
+// Search returns an iterator over the caller's todos matching query,
+// most relevant first, fetching them pageSize at a time. A pageSize of
+// zero uses the server's default.
+func (c *Client) Search(ctx context.Context, query string, pageSize int) *SearchIterator {
+	return newSearchIterator(func(pageToken string) ([]todo.SearchResult, string, error) {
+		ctx, cancel := c.context(ctx)
+		defer cancel()
+
+		resp, err := c.todos.Search(ctx, &pb.SearchRequest{
+			Query:     query,
+			PageSize:  int32(pageSize),
+			PageToken: pageToken,
+		})
+		if err != nil {
+			return nil, "", wrapError("search", err)
+		}
+		results := make([]todo.SearchResult, len(resp.Results))
+		for i, r := range resp.Results {
+			t, err := fromProto(r.Todo)
+			if err != nil {
+				return nil, "", err
+			}
+			results[i] = todo.SearchResult{
+				Todo:               t,
+				Rank:               float64(r.Rank),
+				TitleSnippet:       r.TitleSnippet,
+				DescriptionSnippet: r.DescriptionSnippet,
+			}
+		}
+		return results, resp.NextPageToken, nil
+	})
+}
+
+// toProto converts the fields of t that are set by clients.
+func toProto(op string, t todo.Todo) (*pb.Todo, error) {
+	tProto := &pb.Todo{Id: int64(t.ID), Title: t.Title, Description: t.Description, Priority: t.Priority}
+	if !t.Reminder.IsZero() {
+		r, err := ptypes.TimestampProto(t.Reminder)
+		if err != nil {
+			return nil, &Error{Op: op, Code: codes.InvalidArgument, Message: fmt.Sprintf("invalid reminder: %v", err)}
+		}
+		tProto.Reminder = r
+	}
+	return tProto, nil
+}
+
+func fromProto(tProto *pb.Todo) (todo.Todo, error) {
+	t := todo.Todo{
+		OwnerID:     tProto.OwnerId,
+		Title:       tProto.Title,
+		Description: tProto.Description,
+		Priority:    tProto.Priority,
+	}
+	t.ID = uint(tProto.Id)
+
+	var err error
+	if t.Reminder, err = fromTimestamp(tProto.Reminder); err != nil {
+		return todo.Todo{}, err
+	}
+	if t.CreatedAt, err = fromTimestamp(tProto.CreatedAt); err != nil {
+		return todo.Todo{}, err
+	}
+	if t.UpdatedAt, err = fromTimestamp(tProto.UpdatedAt); err != nil {
+		return todo.Todo{}, err
+	}
+	if tProto.DeletedAt != nil {
+		deletedAt, err := fromTimestamp(tProto.DeletedAt)
+		if err != nil {
+			return todo.Todo{}, err
+		}
+		t.DeletedAt = &deletedAt
+	}
+
+	return t, nil
+}
+
+// fromTimestamp returns the local time of ts, or the zero time if ts is
+// unset or the zero time.
+func fromTimestamp(ts *tspb.Timestamp) (time.Time, error) {
+	if ts == nil {
+		return time.Time{}, nil
+	}
+	t, err := ptypes.Timestamp(ts)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp from server: %v", err)
+	}
+	if t.IsZero() {
+		return t, nil
+	}
+	return t.Local(), nil
+}
+
+// ReminderLayout is the local time layout, without seconds, accepted by
+// ParseReminder.
+const ReminderLayout = "2006-01-02 15:04"
+
+// ParseReminder parses a duration from now such as 2h or +30m, an
+// RFC 3339 time, or a local time in ReminderLayout.
+func ParseReminder(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(strings.TrimPrefix(s, "+")); err == nil {
+		return now.Add(d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(ReminderLayout, s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is neither a time nor a duration", s)
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Errors that an *Error matches with errors.Is, by the code of its status.
+var (
+	ErrNotFound         = errors.New("not found")
+	ErrInvalidArgument  = errors.New("invalid argument")
+	ErrUnauthenticated  = errors.New("unauthenticated")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrUnavailable      = errors.New("unavailable")
+	ErrTimeout          = errors.New("timed out")
+)
+
+var codeErrors = map[codes.Code]error{
+	codes.NotFound:          ErrNotFound,
+	codes.InvalidArgument:   ErrInvalidArgument,
+	codes.Unauthenticated:   ErrUnauthenticated,
+	codes.PermissionDenied:  ErrPermissionDenied,
+	codes.ResourceExhausted: ErrRateLimited,
+	codes.Unavailable:       ErrUnavailable,
+	codes.DeadlineExceeded:  ErrTimeout,
+}
+
+// Error is a failed call.
+type Error struct {
+	// Op is the method that failed, such as read.
+	Op      string
+	Code    codes.Code
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Op, e.Message)
+}
+
+// Is reports whether target is the error of e's code, such as
+// ErrNotFound for codes.NotFound.
+func (e *Error) Is(target error) bool {
+	err, ok := codeErrors[e.Code]
+	return ok && err == target
+}
+
+// GRPCStatus returns the status of e, so that status.Code and
+// status.Convert work on it.
+func (e *Error) GRPCStatus() *status.Status {
+	return status.New(e.Code, e.Message)
+}
+
+// wrapError converts the error of a call to an *Error.
+func wrapError(op string, err error) error {
+	switch err {
+	case context.DeadlineExceeded:
+		return &Error{Op: op, Code: codes.DeadlineExceeded, Message: err.Error()}
+	case context.Canceled:
+		return &Error{Op: op, Code: codes.Canceled, Message: err.Error()}
+	}
+
+	st := status.Convert(err)
+	return &Error{Op: op, Code: st.Code(), Message: st.Message()}
+}
@@ -0,0 +1,138 @@
+package client
+
+import "github.com/dikaeinstein/prototodo/pkg/todo"
+
+// pager pages through results, calling fetch for the page at a token,
+// which stores the page and returns its length and the token of the next
+// page, or "" if it is the last.
+type pager struct {
+	fetch     func(pageToken string) (int, string, error)
+	n, i      int
+	nextToken string
+	started   bool
+	err       error
+}
+
+func newPager(fetch func(pageToken string) (int, string, error)) pager {
+	return pager{fetch: fetch, i: -1}
+}
+
+// next advances to the next result, fetching pages as needed.
+func (p *pager) next() bool {
+	if p.err != nil {
+		return false
+	}
+
+	p.i++
+	for p.i >= p.n {
+		if p.started && p.nextToken == "" {
+			return false
+		}
+		p.started = true
+		p.n, p.nextToken, p.err = p.fetch(p.nextToken)
+		p.i = 0
+		if p.err != nil {
+			p.n = 0
+			return false
+		}
+	}
+	return true
+}
+
+// Iterator iterates over todos, fetching pages from the server as
+// needed:
+//
+//	it := c.List(ctx)
+//	for it.Next() {
+//		fmt.Println(it.Todo().Title)
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator struct {
+	pager
+	page []todo.Todo
+}
+
+func newIterator(fetch func(pageToken string) ([]todo.Todo, string, error)) *Iterator {
+	it := &Iterator{}
+	it.pager = newPager(func(pageToken string) (int, string, error) {
+		var (
+			next string
+			err  error
+		)
+		it.page, next, err = fetch(pageToken)
+		return len(it.page), next, err
+	})
+	return it
+}
+
+// Next advances to the next todo, returning false when there are no
+// more or fetching them failed.
+func (it *Iterator) Next() bool {
+	return it.next()
+}
+
+// Todo returns the current todo.
+func (it *Iterator) Todo() todo.Todo {
+	return it.page[it.i]
+}
+
+// Err returns the error that ended the iteration, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// All returns the remaining todos.
+func (it *Iterator) All() ([]todo.Todo, error) {
+	var tt []todo.Todo
+	for it.Next() {
+		tt = append(tt, it.Todo())
+	}
+	return tt, it.Err()
+}
+
+// SearchIterator iterates over search results like Iterator does over
+// todos.
+type SearchIterator struct {
+	pager
+	page []todo.SearchResult
+}
+
+func newSearchIterator(fetch func(pageToken string) ([]todo.SearchResult, string, error)) *SearchIterator {
+	it := &SearchIterator{}
+	it.pager = newPager(func(pageToken string) (int, string, error) {
+		var (
+			next string
+			err  error
+		)
+		it.page, next, err = fetch(pageToken)
+		return len(it.page), next, err
+	})
+	return it
+}
+
+// Next advances to the next result, returning false when there are no
+// more or fetching them failed.
+func (it *SearchIterator) Next() bool {
+	return it.next()
+}
+
+// Result returns the current todo with its rank and snippets.
+func (it *SearchIterator) Result() todo.SearchResult {
+	return it.page[it.i]
+}
+
+// Err returns the error that ended the iteration, if any.
+func (it *SearchIterator) Err() error {
+	return it.err
+}
+
+// All returns the remaining results.
+func (it *SearchIterator) All() ([]todo.SearchResult, error) {
+	var rr []todo.SearchResult
+	for it.Next() {
+		rr = append(rr, it.Result())
+	}
+	return rr, it.Err()
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+)
+
+func TestIterator(t *testing.T) {
+	page := func(titles ...string) []todo.Todo {
+		tt := make([]todo.Todo, len(titles))
+		for i, title := range titles {
+			tt[i].Title = title
+		}
+		return tt
+	}
+	errFetch := errors.New("fetch failed")
+
+	tests := []struct {
+		name    string
+		pages   map[string][]todo.Todo
+		next    map[string]string
+		failAt  string
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "one page",
+			pages: map[string][]todo.Todo{"": page("a", "b")},
+			want:  []string{"a", "b"},
+		},
+		{
+			name:  "pages",
+			pages: map[string][]todo.Todo{"": page("a"), "2": page("b", "c")},
+			next:  map[string]string{"": "2"},
+			want:  []string{"a", "b", "c"},
+		},
+		{
+			name:  "empty page in between",
+			pages: map[string][]todo.Todo{"": page("a"), "2": nil, "3": page("b")},
+			next:  map[string]string{"": "2", "2": "3"},
+			want:  []string{"a", "b"},
+		},
+		{
+			name:  "no todos",
+			pages: map[string][]todo.Todo{"": nil},
+		},
+		{
+			name:    "failed fetch",
+			pages:   map[string][]todo.Todo{"": page("a")},
+			next:    map[string]string{"": "2"},
+			failAt:  "2",
+			want:    []string{"a"},
+			wantErr: errFetch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := newIterator(func(token string) ([]todo.Todo, string, error) {
+				if token == tt.failAt && token != "" {
+					return nil, "", errFetch
+				}
+				return tt.pages[token], tt.next[token], nil
+			})
+
+			todos, err := it.All()
+			var got []string
+			for _, td := range todos {
+				got = append(got, td.Title)
+			}
+			if !reflect.DeepEqual(got, tt.want) || err != tt.wantErr {
+				t.Errorf("All() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+			if it.Next() {
+				t.Errorf("Next() = true after the end")
+			}
+		})
+	}
+}
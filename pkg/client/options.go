@@ -0,0 +1,106 @@
+package client
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// DefaultAddress is the address New connects to without WithAddress.
+const DefaultAddress = "localhost:10000"
+
+// DefaultTimeout is how long calls may take when their context has no
+// deadline, unless changed with WithTimeout.
+const DefaultTimeout = 10 * time.Second
+
+// Option configures a Client.
+type Option func(*options)
+
+type options struct {
+	address     string
+	tls         bool
+	rootCert    string
+	certFile    string
+	keyFile     string
+	token       string
+	timeout     time.Duration
+	retry       *RetryPolicy
+	l           *zap.Logger
+	dialOptions []grpc.DialOption
+	conn        *grpc.ClientConn
+}
+
+// WithAddress sets the host:port of the server.
+func WithAddress(address string) Option {
+	return func(o *options) {
+		o.address = address
+	}
+}
+
+// WithTLS connects over TLS, verifying the server certificate against
+// the PEM encoded CA in rootCert, or the system roots if it is empty.
+func WithTLS(rootCert string) Option {
+	return func(o *options) {
+		o.tls = true
+		o.rootCert = rootCert
+	}
+}
+
+// WithClientCert presents the certificate pair in certFile and keyFile
+// to servers that require mutual TLS. The files are reloaded when they
+// change. It implies TLS.
+func WithClientCert(certFile, keyFile string) Option {
+	return func(o *options) {
+		o.tls = true
+		o.certFile = certFile
+		o.keyFile = keyFile
+	}
+}
+
+// WithToken sends token as a bearer token with every call.
+func WithToken(token string) Option {
+	return func(o *options) {
+		o.token = token
+	}
+}
+
+// WithTimeout sets how long calls may take when their context has no
+// deadline. Zero means calls only end with their context.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithRetry retries calls that fail because the server is unavailable,
+// as described by p.
+func WithRetry(p RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = &p
+	}
+}
+
+// WithLogger logs retries to l.
+func WithLogger(l *zap.Logger) Option {
+	return func(o *options) {
+		o.l = l
+	}
+}
+
+// WithDialOptions adds options to those the connection is dialed with,
+// such as interceptors.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, opts...)
+	}
+}
+
+// WithConn makes the Client use conn instead of dialing, in which case
+// the options configuring the connection are ignored and Close doesn't
+// close conn.
+func WithConn(conn *grpc.ClientConn) Option {
+	return func(o *options) {
+		o.conn = conn
+	}
+}
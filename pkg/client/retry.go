@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy retries idempotent calls, and calls made with an idempotency
+// key, that fail with Unavailable, such as while the server restarts. Calls
+// made with an idempotency key are also retried when they fail with Aborted,
+// which the server answers while the first attempt is still in progress, to
+// get its response. The n-th retry waits a random time of up to
+// InitialBackoff * Multiplier^(n-1), capped at MaxBackoff.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts, including the first one.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+// DefaultRetryPolicy makes up to 5 attempts over about 3 seconds.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Multiplier:     2,
+}
+
+// idempotentMethods are the methods that are safe to call again. Others
+// are when made with an idempotency key, which makes the server replay the
+// response to the first call.
+var idempotentMethods = map[string]bool{
+	"/todo.v1.TodoService/Read":    true,
+	"/todo.v1.TodoService/ReadAll": true,
+	"/todo.v1.TodoService/Search":  true,
+}
+
+func (p RetryPolicy) unaryInterceptor(l *zap.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		keyed := hasIdempotencyKey(req)
+		if !idempotentMethods[method] && !keyed {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		for attempt := 1; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil && attempt > 1 {
+				l.Info("call succeeded after retrying",
+					zap.String("method", method),
+					zap.Int("attempts", attempt))
+			}
+			if !retryable(status.Code(err), keyed) || attempt >= p.MaxAttempts {
+				return err
+			}
+
+			backoff := p.backoff(attempt)
+			l.Warn("retrying call",
+				zap.String("method", method),
+				zap.Int("attempt", attempt),
+				zap.Int("max_attempts", p.MaxAttempts),
+				zap.Duration("backoff", backoff),
+				zap.Error(err))
+
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(backoff):
+			}
+		}
+	}
+}
+
+// backoff returns how long to wait after the given failed attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		d *= p.Multiplier
+	}
+	if max := float64(p.MaxBackoff); p.MaxBackoff > 0 && d > max {
+		d = max
+	}
+	// Full jitter spreads out the retries of clients that failed together.
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// retryable reports whether a call that failed with code may succeed if
+// it is made again.
+func retryable(code codes.Code, keyed bool) bool {
+	return code == codes.Unavailable || (keyed && code == codes.Aborted)
+}
+
+func hasIdempotencyKey(req interface{}) bool {
+	r, ok := req.(interface{ GetIdempotencyKey() string })
+	return ok && r.GetIdempotencyKey() != ""
+}
+
+// NewIdempotencyKey returns a random idempotency key. Retries of a call
+// must send the same key as the first attempt.
+func NewIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := crand.Read(b); err != nil {
+		// A key the server rejects is better than a colliding one.
+		panic(fmt.Sprintf("failed to generate idempotency key: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/dikaeinstein/prototodo/pkg/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 2}
+
+	tests := []struct {
+		name         string
+		method       string
+		req          interface{}
+		code         codes.Code
+		wantAttempts int
+	}{
+		{"idempotent unavailable", "/todo.v1.TodoService/Read", &pb.ReadRequest{}, codes.Unavailable, 3},
+		{"idempotent aborted", "/todo.v1.TodoService/Read", &pb.ReadRequest{}, codes.Aborted, 1},
+		{"keyed unavailable", "/todo.v1.TodoService/Create", &pb.CreateRequest{IdempotencyKey: "k"}, codes.Unavailable, 3},
+		{"keyed aborted", "/todo.v1.TodoService/Create", &pb.CreateRequest{IdempotencyKey: "k"}, codes.Aborted, 3},
+		{"unkeyed unavailable", "/todo.v1.TodoService/Create", &pb.CreateRequest{}, codes.Unavailable, 1},
+		{"keyed not found", "/todo.v1.TodoService/Delete", &pb.DeleteRequest{IdempotencyKey: "k"}, codes.NotFound, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				attempts++
+				return status.Error(tt.code, "failed")
+			}
+
+			err := p.unaryInterceptor(zap.NewNop())(context.Background(), tt.method, tt.req, nil, nil, invoker)
+			if status.Code(err) != tt.code {
+				t.Errorf("err = %v, want code %v", err, tt.code)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	pb "github.com/dikaeinstein/prototodo/pkg/proto"
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+	"github.com/dikaeinstein/prototodo/pkg/todo/codec"
+	"github.com/golang/protobuf/ptypes"
+)
+
+// ImportResult reports on an import.
+type ImportResult struct {
+	// Imported is the number of todos created, or that would have been
+	// in a dry run.
+	Imported int
+	// Errors are the lines that weren't imported, in order, whether they
+	// couldn't be decoded or the server rejected them.
+	Errors []*codec.LineError
+	DryRun bool
+}
+
+// Import streams the todos read from dec to the server, which creates
+// them unless dryRun. Lines that fail are reported in the result rather
+// than ending the import, which fails only if dec or the call does.
+func (c *Client) Import(ctx context.Context, dec codec.Decoder, dryRun bool) (ImportResult, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+
+	stream, err := c.todos.Import(ctx)
+	if err != nil {
+		return ImportResult{}, wrapError("import", err)
+	}
+
+	res := ImportResult{DryRun: dryRun}
+	for {
+		t, line, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		var lerr *codec.LineError
+		if errors.As(err, &lerr) {
+			res.Errors = append(res.Errors, lerr)
+			continue
+		}
+		if err != nil {
+			return ImportResult{}, err
+		}
+
+		tProto, err := toProto("import", t)
+		if err != nil {
+			res.Errors = append(res.Errors, &codec.LineError{Line: line, Err: err})
+			continue
+		}
+		if !t.CreatedAt.IsZero() {
+			if tProto.CreatedAt, err = ptypes.TimestampProto(t.CreatedAt); err != nil {
+				res.Errors = append(res.Errors, &codec.LineError{Line: line, Err: fmt.Errorf("invalid created_at: %v", err)})
+				continue
+			}
+		}
+		err = stream.Send(&pb.ImportRequest{Todo: tProto, Line: int32(line), DryRun: dryRun})
+		if err == io.EOF {
+			// The server ended the call, CloseAndRecv returns why.
+			break
+		}
+		if err != nil {
+			return ImportResult{}, wrapError("import", err)
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return ImportResult{}, wrapError("import", err)
+	}
+
+	res.Imported = int(resp.Imported)
+	for _, e := range resp.Errors {
+		res.Errors = append(res.Errors, &codec.LineError{Line: int(e.Line), Err: errors.New(e.Message)})
+	}
+	sort.SliceStable(res.Errors, func(i, j int) bool {
+		return res.Errors[i].Line < res.Errors[j].Line
+	})
+
+	return res, nil
+}
+
+// Export returns an iterator over all the caller's todos, streamed from
+// the server. Cancel ctx to stop the stream before the end.
+func (c *Client) Export(ctx context.Context) *Iterator {
+	var (
+		stream pb.TodoService_ExportClient
+		cancel context.CancelFunc
+	)
+
+	// Each fetch returns one todo, and a token of "more" until the stream
+	// ends.
+	return newIterator(func(string) ([]todo.Todo, string, error) {
+		if stream == nil {
+			var sctx context.Context
+			sctx, cancel = c.context(ctx)
+			var err error
+			if stream, err = c.todos.Export(sctx, &pb.ExportRequest{}); err != nil {
+				cancel()
+				return nil, "", wrapError("export", err)
+			}
+		}
+
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			cancel()
+			return nil, "", nil
+		}
+		if err != nil {
+			cancel()
+			return nil, "", wrapError("export", err)
+		}
+		t, err := fromProto(resp.Todo)
+		if err != nil {
+			cancel()
+			return nil, "", err
+		}
+		return []todo.Todo{t}, "more", nil
+	})
+}
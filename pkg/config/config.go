@@ -1,67 +1,249 @@
 package config
 
 import (
-	"log"
-	"os"
-	"strconv"
+	"fmt"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/dikaeinstein/prototodo/pkg/logger"
 )
 
 // Config is the configuration of the server.
+//
+// Every field can be set in the config file under the key in its config
+// tag and in the environment variable in its env tag. See Load.
 type Config struct {
-	AppEnv   string
-	TLS      bool
-	DBName   string
-	Port     int
-	KeyFile  string
-	CertFile string
-	LogLevel int
-	RootCert string
+	AppEnv   string `config:"app_env" env:"APP_ENV"`
+	TLS      bool   `config:"tls" env:"TLS"`
+	DBName   string `config:"db_name" env:"DB_NAME"`
+	Store    string `config:"store" env:"STORE"`
+	Port     int    `config:"port" env:"PORT"`
+	KeyFile  string `config:"key_file" env:"KEY_FILE"`
+	CertFile string `config:"cert_file" env:"CERT_FILE"`
+	LogLevel string `config:"log_level" env:"LOG_LEVEL"`
+	RootCert string `config:"root_cert" env:"ROOT_CERT"`
+
+	// Log output, see logger.Options. An empty LogFormat picks json when
+	// AppEnv is production or prod and console elsewhere.
+	LogFormat           string `config:"log_format" env:"LOG_FORMAT"`
+	LogTimeFormat       string `config:"log_time_format" env:"LOG_TIME_FORMAT"`
+	LogSampleInitial    int    `config:"log_sample_initial" env:"LOG_SAMPLE_INITIAL"`
+	LogSampleThereafter int    `config:"log_sample_thereafter" env:"LOG_SAMPLE_THEREAFTER"`
+	LogCaller           bool   `config:"log_caller" env:"LOG_CALLER"`
+	LogStacktraceLevel  string `config:"log_stacktrace_level" env:"LOG_STACKTRACE_LEVEL"`
+	LogFile             string `config:"log_file" env:"LOG_FILE"`
+	LogMaxSizeMB        int    `config:"log_max_size_mb" env:"LOG_MAX_SIZE_MB"`
+	LogMaxBackups       int    `config:"log_max_backups" env:"LOG_MAX_BACKUPS"`
+	LogMaxAgeDays       int    `config:"log_max_age_days" env:"LOG_MAX_AGE_DAYS"`
+	LogCompress         bool   `config:"log_compress" env:"LOG_COMPRESS"`
+
+	// GatewayPort serves the REST/JSON gateway, 0 disables it.
+	GatewayPort int `config:"gateway_port" env:"GATEWAY_PORT"`
+	// GRPCWebPort serves grpc-web to browsers, 0 disables it.
+	GRPCWebPort int `config:"grpc_web_port" env:"GRPC_WEB_PORT"`
+	// CORSAllowedOrigins is a comma separated list of origins browsers
+	// may call grpc-web from, * allows any origin.
+	CORSAllowedOrigins string `config:"cors_allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	// Reflection registers the gRPC server reflection service.
+	Reflection bool `config:"reflection" env:"REFLECTION"`
+	// MetricsPort serves prometheus metrics over HTTP, 0 disables it.
+	MetricsPort int `config:"metrics_port" env:"METRICS_PORT"`
+	// HealthPort serves the /healthz and /readyz probes over HTTP. They
+	// are served on MetricsPort if it is 0, so one of them must be set.
+	HealthPort int `config:"health_port" env:"HEALTH_PORT"`
+	// AdminPort serves PUT /loglevel on localhost only, 0 disables it.
+	AdminPort int `config:"admin_port" env:"ADMIN_PORT"`
+	// Dependencies are checked every HealthCheckInterval to set the
+	// gRPC health status.
+	HealthCheckInterval time.Duration `config:"health_check_interval" env:"HEALTH_CHECK_INTERVAL"`
+	HealthCheckTimeout  time.Duration `config:"health_check_timeout" env:"HEALTH_CHECK_TIMEOUT"`
+	// OTLPEndpoint is the OpenTelemetry collector spans are exported to.
+	// Servers write spans to stderr if it is empty.
+	OTLPEndpoint string `config:"otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+
+	// On SIGINT or SIGTERM health checks fail for ShutdownDrainPeriod,
+	// then in-flight requests get up to ShutdownTimeout to finish.
+	ShutdownDrainPeriod time.Duration `config:"shutdown_drain_period" env:"SHUTDOWN_DRAIN_PERIOD"`
+	ShutdownTimeout     time.Duration `config:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+
+	// Mutual TLS. ClientAuth is one of none, optional or required.
+	ClientCAFile       string        `config:"client_ca_file" env:"CLIENT_CA_FILE"`
+	ClientAuth         string        `config:"client_auth" env:"CLIENT_AUTH"`
+	ClientCertFile     string        `config:"client_cert_file" env:"CLIENT_CERT_FILE"`
+	ClientKeyFile      string        `config:"client_key_file" env:"CLIENT_KEY_FILE"`
+	CertReloadInterval time.Duration `config:"cert_reload_interval" env:"CERT_RELOAD_INTERVAL"`
+
+	// JWT bearer token authentication, enabled when a key file is set.
+	JWTHMACKeyFile      string `config:"jwt_hmac_key_file" env:"JWT_HMAC_KEY_FILE"`
+	JWTRSAPublicKeyFile string `config:"jwt_rsa_public_key_file" env:"JWT_RSA_PUBLIC_KEY_FILE"`
+	JWTAudience         string `config:"jwt_audience" env:"JWT_AUDIENCE"`
+
+	// PolicyFile maps roles to the gRPC methods they may call.
+	PolicyFile string `config:"policy_file" env:"POLICY_FILE"`
+
+	// RateLimits is a comma separated list of method=rate:burst token
+	// buckets per caller, with * as the default for other methods.
+	RateLimits string `config:"rate_limits" env:"RATE_LIMITS"`
+
+	// IdempotencyKeyTTL is how long the responses to calls made with an
+	// idempotency key are kept to be replayed to retries.
+	IdempotencyKeyTTL time.Duration `config:"idempotency_key_ttl" env:"IDEMPOTENCY_KEY_TTL"`
+
+	// WebhookMaxAttempts is how many times a webhook delivery is attempted
+	// before it is dead-lettered, and WebhookTimeout how long each attempt
+	// may take.
+	WebhookMaxAttempts int           `config:"webhook_max_attempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookTimeout     time.Duration `config:"webhook_timeout" env:"WEBHOOK_TIMEOUT"`
+
+	// Server is the address the client connects to, localhost:Port if
+	// empty. Commands of the client time out after Timeout.
+	Server  string        `config:"server" env:"SERVER"`
+	Timeout time.Duration `config:"timeout" env:"TIMEOUT"`
+	// Token is the bearer token sent by the client.
+	Token string `config:"token" env:"TOKEN"`
+	// The client retries calls that fail because the server
+	// is unavailable up to RetryMaxAttempts times in all, waiting a
+	// jittered backoff that doubles from RetryInitialBackoff up to
+	// RetryMaxBackoff. 1 disables retries.
+	RetryMaxAttempts    int           `config:"retry_max_attempts" env:"RETRY_MAX_ATTEMPTS"`
+	RetryInitialBackoff time.Duration `config:"retry_initial_backoff" env:"RETRY_INITIAL_BACKOFF"`
+	RetryMaxBackoff     time.Duration `config:"retry_max_backoff" env:"RETRY_MAX_BACKOFF"`
 }
 
-// New creates an instance of config.
-func New() Config {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
+// Default returns the configuration used for values that aren't set.
+func Default() Config {
+	return Config{
+		AppEnv:   "development",
+		DBName:   "prototodos",
+		Store:    "postgres",
+		Port:     10000,
+		LogLevel: "info",
+
+		LogTimeFormat: "2006-01-02T15:04:05Z07:00",
+		LogMaxSizeMB:  100,
+		LogMaxBackups: 3,
+		LogMaxAgeDays: 28,
+
+		GatewayPort: 8080,
+		GRPCWebPort: 8081,
+		MetricsPort: 10001,
+
+		HealthCheckInterval: 10 * time.Second,
+		HealthCheckTimeout:  2 * time.Second,
+
+		ShutdownDrainPeriod: 5 * time.Second,
+		ShutdownTimeout:     30 * time.Second,
+
+		ClientAuth:         "none",
+		CertReloadInterval: time.Minute,
+
+		IdempotencyKeyTTL: 24 * time.Hour,
+
+		WebhookMaxAttempts: 8,
+		WebhookTimeout:     10 * time.Second,
+
+		Timeout:             10 * time.Second,
+		RetryMaxAttempts:    5,
+		RetryInitialBackoff: 200 * time.Millisecond,
+		RetryMaxBackoff:     2 * time.Second,
 	}
+}
 
-	return Config{
-		AppEnv:   getEnv("APP_ENV", "development"),
-		TLS:      getEnvAsBool("TLS", false),
-		DBName:   getEnv("DB_NAME", "prototodos"),
-		KeyFile:  getEnv("KEY_FILE", ""),
-		CertFile: getEnv("CERT_FILE", ""),
-		Port:     getEnvAsInt("PORT", 10000),
-		LogLevel: getEnvAsInt("LOG_LEVEL", 0),
-		RootCert: getEnv("ROOT_CERT", ""),
+// checker collects the errors of failed checks.
+type checker struct {
+	errs Errors
+}
+
+func (c *checker) check(ok bool, format string, args ...interface{}) {
+	if !ok {
+		c.errs = append(c.errs, fmt.Errorf(format, args...))
 	}
 }
 
-// Simple helper function to read an environment or return a default value
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+func (c *checker) err() error {
+	if len(c.errs) > 0 {
+		return c.errs
 	}
-	return defaultValue
+	return nil
 }
 
-// Helper to read an environment variable into a bool or return a default value
-func getEnvAsBool(name string, defaultVal bool) bool {
-	valStr := getEnv(name, "")
-	if val, err := strconv.ParseBool(valStr); err == nil {
-		return val
+// Validate checks every value the server uses and reports all the invalid
+// ones at once.
+func (c Config) Validate() error {
+	var v checker
+	check := v.check
+
+	check(c.Store == "postgres" || c.Store == "memory", "store: must be postgres or memory, got %q", c.Store)
+	check(c.Port > 0 && c.Port <= 65535, "port: must be between 1 and 65535, got %d", c.Port)
+	for name, port := range map[string]int{
+		"gateway_port":  c.GatewayPort,
+		"grpc_web_port": c.GRPCWebPort,
+		"metrics_port":  c.MetricsPort,
+		"health_port":   c.HealthPort,
+		"admin_port":    c.AdminPort,
+	} {
+		check(port >= 0 && port <= 65535, "%s: must be between 0 and 65535, got %d", name, port)
+	}
+	check(c.MetricsPort != 0 || c.HealthPort != 0, "health_port: must be set when metrics_port is 0, which would disable the /healthz and /readyz probes")
+	_, err := logger.ParseLevel(c.LogLevel)
+	check(err == nil, "log_level: must be debug, info, warn or error, got %q", c.LogLevel)
+	check(c.LogFormat == "" || c.LogFormat == "console" || c.LogFormat == "json" || c.LogFormat == "logfmt",
+		"log_format: must be console, json or logfmt, got %q", c.LogFormat)
+	check(c.LogSampleInitial >= 0 && c.LogSampleThereafter >= 0, "log_sample_initial and log_sample_thereafter: must not be negative")
+	check(c.LogSampleInitial <= 0 || c.LogSampleThereafter >= 1,
+		"log_sample_thereafter: must be at least 1 when log_sample_initial is set, got %d", c.LogSampleThereafter)
+	if c.LogStacktraceLevel != "" {
+		_, err := logger.ParseLevel(c.LogStacktraceLevel)
+		check(err == nil, "log_stacktrace_level: must be debug, info, warn or error, got %q", c.LogStacktraceLevel)
 	}
-	return defaultVal
+	check(c.LogFile == "" || c.LogMaxSizeMB > 0, "log_max_size_mb: must be positive, got %d", c.LogMaxSizeMB)
+	check(c.LogMaxBackups >= 0 && c.LogMaxAgeDays >= 0, "log_max_backups and log_max_age_days: must not be negative")
+	check(c.ClientAuth == "none" || c.ClientAuth == "optional" || c.ClientAuth == "required",
+		"client_auth: must be none, optional or required, got %q", c.ClientAuth)
+	check(!c.TLS || (c.CertFile != "" && c.KeyFile != ""), "cert_file and key_file: must be set when tls is")
+	check(c.ClientAuth == "none" || c.ClientCAFile != "", "client_ca_file: must be set when client_auth is %s", c.ClientAuth)
+	check(c.HealthCheckInterval > 0, "health_check_interval: must be positive, got %v", c.HealthCheckInterval)
+	check(c.HealthCheckTimeout > 0, "health_check_timeout: must be positive, got %v", c.HealthCheckTimeout)
+	check(c.ShutdownDrainPeriod >= 0, "shutdown_drain_period: must not be negative, got %v", c.ShutdownDrainPeriod)
+	check(c.ShutdownTimeout > 0, "shutdown_timeout: must be positive, got %v", c.ShutdownTimeout)
+	check(c.IdempotencyKeyTTL > 0, "idempotency_key_ttl: must be positive, got %v", c.IdempotencyKeyTTL)
+	check(c.WebhookMaxAttempts >= 1, "webhook_max_attempts: must be at least 1, got %d", c.WebhookMaxAttempts)
+	check(c.WebhookTimeout > 0, "webhook_timeout: must be positive, got %v", c.WebhookTimeout)
+	check(c.CertReloadInterval > 0, "cert_reload_interval: must be positive, got %v", c.CertReloadInterval)
+
+	return v.err()
+}
+
+// ValidateClient checks every value the client uses and reports all the
+// invalid ones at once.
+func (c Config) ValidateClient() error {
+	var v checker
+	check := v.check
+
+	check(c.Server != "" || (c.Port > 0 && c.Port <= 65535), "port: must be between 1 and 65535 without a server, got %d", c.Port)
+	_, err := logger.ParseLevel(c.LogLevel)
+	check(err == nil, "log_level: must be debug, info, warn or error, got %q", c.LogLevel)
+	check((c.ClientCertFile == "") == (c.ClientKeyFile == ""), "client_cert_file and client_key_file: must be set together")
+	check(c.Timeout > 0, "timeout: must be positive, got %v", c.Timeout)
+	check(c.RetryMaxAttempts >= 1, "retry_max_attempts: must be at least 1, got %d", c.RetryMaxAttempts)
+	check(c.RetryInitialBackoff > 0, "retry_initial_backoff: must be positive, got %v", c.RetryInitialBackoff)
+	check(c.RetryMaxBackoff >= c.RetryInitialBackoff, "retry_max_backoff: must not be less than retry_initial_backoff, got %v", c.RetryMaxBackoff)
+
+	return v.err()
 }
 
-// Simple helper function to read an environment variable into an integer
-// or return a default value
-func getEnvAsInt(name string, defaultVal int) int {
-	valueStr := getEnv(name, "")
-	if value, err := strconv.Atoi(valueStr); err == nil {
-		return value
+// LoggerOptions returns the options of the log output.
+func (c Config) LoggerOptions() logger.Options {
+	return logger.Options{
+		Format:           c.LogFormat,
+		TimeFormat:       c.LogTimeFormat,
+		SampleInitial:    c.LogSampleInitial,
+		SampleThereafter: c.LogSampleThereafter,
+		Caller:           c.LogCaller,
+		StacktraceLevel:  c.LogStacktraceLevel,
+		File:             c.LogFile,
+		MaxSizeMB:        c.LogMaxSizeMB,
+		MaxBackups:       c.LogMaxBackups,
+		MaxAgeDays:       c.LogMaxAgeDays,
+		Compress:         c.LogCompress,
 	}
-	return defaultVal
 }
@@ -0,0 +1,62 @@
+package config
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(c *Config)
+		wantErr bool
+	}{
+		{"defaults", func(c *Config) {}, false},
+		{"tls with cert and key", func(c *Config) {
+			c.TLS, c.CertFile, c.KeyFile = true, "server.crt", "server.key"
+		}, false},
+		{"tls without cert and key", func(c *Config) { c.TLS = true }, true},
+		{"tls without key", func(c *Config) { c.TLS, c.CertFile = true, "server.crt" }, true},
+		{"client-only values", func(c *Config) { c.Timeout, c.RetryMaxAttempts = 0, 0 }, false},
+		{"probes without metrics", func(c *Config) { c.MetricsPort = 0 }, true},
+		{"probes on their own port", func(c *Config) { c.MetricsPort, c.HealthPort = 0, 10003 }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			tt.change(&c)
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(c *Config)
+		wantErr bool
+	}{
+		{"defaults", func(c *Config) {}, false},
+		{"tls without server cert", func(c *Config) { c.TLS = true }, false},
+		{"server-only values", func(c *Config) {
+			c.Store, c.ClientAuth, c.HealthCheckInterval = "", "required", 0
+		}, false},
+		{"client cert without key", func(c *Config) {
+			c.TLS, c.ClientCertFile = true, "client.crt"
+		}, true},
+		{"no timeout", func(c *Config) { c.Timeout = 0 }, true},
+		{"retry backoffs swapped", func(c *Config) {
+			c.RetryInitialBackoff, c.RetryMaxBackoff = c.RetryMaxBackoff, c.RetryInitialBackoff
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			tt.change(&c)
+			if err := c.ValidateClient(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateClient() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
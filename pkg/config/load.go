@@ -0,0 +1,196 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// FileEnv is the environment variable the config file can be
+// given in instead of the -config flag.
+const FileEnv = "CONFIG_FILE"
+
+// Errors collects every problem found while loading or validating
+// the configuration.
+type Errors []error
+
+// Join combines errs into a single Errors, or returns nil if they
+// are all nil.
+func Join(errs ...error) error {
+	var joined Errors
+	for _, err := range errs {
+		switch err := err.(type) {
+		case nil:
+		case Errors:
+			joined = append(joined, err...)
+		default:
+			joined = append(joined, err)
+		}
+	}
+
+	if len(joined) > 0 {
+		return joined
+	}
+	return nil
+}
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "invalid configuration:\n\t" + strings.Join(msgs, "\n\t")
+}
+
+// Load builds the configuration from, in increasing precedence, the
+// defaults, the YAML or TOML file at path if it isn't empty, and the
+// environment, which is read from a .env file too if there is one.
+// Flags are meant to be applied on top and the result validated.
+func Load(path string) (Config, error) {
+	c := Default()
+	var errs Errors
+
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return c, err
+		}
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := values[key]
+			f, ok := fieldByTag(&c, "config", key)
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: unknown key %q", path, key))
+				continue
+			}
+			if err := setField(f, fmt.Sprint(value)); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s: %v", path, key, err))
+			}
+		}
+	}
+
+	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
+		errs = append(errs, fmt.Errorf(".env: %v", err))
+	}
+	v := reflect.ValueOf(&c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Tag.Get("env")
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setField(v.Field(i), value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return c, errs
+	}
+	return c, nil
+}
+
+// FileFromArgs returns the value of the -config flag in args, falling
+// back to $CONFIG_FILE. It lets the file be loaded before the flags
+// are defined, so they can default to its values.
+func FileFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name := strings.TrimLeft(arg, "-")
+		if name == arg {
+			continue
+		}
+		if name == "config" && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(name, "config=") {
+			return strings.TrimPrefix(name, "config=")
+		}
+	}
+
+	return os.Getenv(FileEnv)
+}
+
+// readFile reads the top-level keys of a YAML or TOML file,
+// chosen by its extension.
+func readFile(path string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %v", err)
+		}
+		if err := yaml.Unmarshal(b, &values); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+		}
+	case ".toml":
+		if _, err := toml.DecodeFile(path, &values); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("config file %s is neither .yaml, .yml nor .toml", path)
+	}
+
+	return values, nil
+}
+
+func fieldByTag(c *Config, tag, name string) (reflect.Value, bool) {
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Tag.Get(tag) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// setField parses s into f, rejecting malformed values instead of
+// falling back to the default.
+func setField(f reflect.Value, s string) error {
+	if f.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", s)
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		f.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		f.SetInt(int64(n))
+	default:
+		return fmt.Errorf("unsupported type %s", f.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Check reports whether a dependency is usable.
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs checks of the server's dependencies periodically and
+// publishes the result as the gRPC health status of a service, which
+// Watch subscribers are notified of as it changes.
+type Checker struct {
+	h        *health.Server
+	service  string
+	interval time.Duration
+	timeout  time.Duration
+	l        *zap.Logger
+	checks   []namedCheck
+
+	mu      sync.RWMutex
+	failing map[string]string
+	serving bool
+}
+
+// New creates a Checker that runs its checks every interval, each with
+// timeout, and sets the status of service and the server as a whole
+// on h. The service is NOT_SERVING until the checks first pass.
+func New(h *health.Server, service string, interval, timeout time.Duration, l *zap.Logger) *Checker {
+	c := &Checker{
+		h:        h,
+		service:  service,
+		interval: interval,
+		timeout:  timeout,
+		l:        l,
+		failing:  make(map[string]string),
+	}
+	c.setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	return c
+}
+
+// Add registers a check of the named dependency. It must be called
+// before Run.
+func (c *Checker) Add(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name, check})
+}
+
+// Run checks the dependencies right away and then every interval
+// until stop is closed.
+func (c *Checker) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.checkAll()
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Checker) checkAll() {
+	failing := make(map[string]string)
+	for _, nc := range c.checks {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		err := nc.check(ctx)
+		cancel()
+		if err != nil {
+			failing[nc.name] = err.Error()
+		}
+	}
+	serving := len(failing) == 0
+
+	c.mu.Lock()
+	changed := serving != c.serving
+	c.failing = failing
+	c.serving = serving
+	c.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if serving {
+		c.l.Info("dependencies are healthy, serving")
+		c.setStatus(healthpb.HealthCheckResponse_SERVING)
+	} else {
+		c.l.Error("dependencies are unhealthy, not serving", zap.Any("failing", failing))
+		c.setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+func (c *Checker) setStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	c.h.SetServingStatus("", status)
+	c.h.SetServingStatus(c.service, status)
+}
+
+// Healthz reports that the process is alive. It doesn't depend on
+// the checks, so a restart isn't triggered by an outage of a dependency.
+func (c *Checker) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok"})
+}
+
+// Readyz reports whether the server should receive traffic: the checks
+// pass and the server isn't shutting down.
+func (c *Checker) Readyz(w http.ResponseWriter, r *http.Request) {
+	resp, err := c.h.Check(r.Context(), &healthpb.HealthCheckRequest{Service: c.service})
+	status := healthpb.HealthCheckResponse_UNKNOWN
+	if err == nil {
+		status = resp.Status
+	}
+
+	c.mu.RLock()
+	body := map[string]interface{}{"status": status.String(), "failing": c.failing}
+	c.mu.RUnlock()
+
+	code := http.StatusOK
+	if status != healthpb.HealthCheckResponse_SERVING {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, body)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
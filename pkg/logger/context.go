@@ -0,0 +1,25 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// NewContext returns a copy of ctx that carries the logger l.
+func NewContext(ctx context.Context, l *zap.Logger) context.Context {
+	return ctxzap.ToContext(ctx, l)
+}
+
+// FromContext returns the request-scoped logger stored in ctx,
+// or a no-op logger if there is none.
+func FromContext(ctx context.Context) *zap.Logger {
+	return ctxzap.Extract(ctx)
+}
+
+// AddFields adds fields to the request-scoped logger stored in ctx,
+// including the log line written when the request finishes.
+func AddFields(ctx context.Context, fields ...zap.Field) {
+	ctxzap.AddFields(ctx, fields...)
+}
@@ -0,0 +1,264 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Root is the name the level of the root logger is set under.
+const Root = ""
+
+// ParseLevel parses a level name such as debug, info, warn or error.
+// The numeric zapcore levels, -1 for debug to 5 for fatal, are
+// accepted too.
+func ParseLevel(s string) (zapcore.Level, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < int(zapcore.DebugLevel) || n > int(zapcore.FatalLevel) {
+			return zapcore.InfoLevel, fmt.Errorf("unknown log level %q", s)
+		}
+		return zapcore.Level(n), nil
+	}
+
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(strings.ToLower(s))); err != nil {
+		return zapcore.InfoLevel, fmt.Errorf("unknown log level %q", s)
+	}
+	return l, nil
+}
+
+// Levels holds the level of the root logger and of named loggers, and
+// lets them be changed while the loggers are in use.
+//
+// A level set for a name applies to every logger with that name as one
+// of the dot separated parts of its own, the rightmost part winning:
+// setting storage to debug enables debug logs of grpc.storage, but not
+// the other logs of grpc. Loggers without a level follow the root.
+type Levels struct {
+	mu   sync.Mutex
+	root zapcore.Level
+	// named holds the levels set for named loggers.
+	named map[string]zapcore.Level
+	// gen counts the changes of each name, so a revert only undoes
+	// the change that scheduled it.
+	gen map[string]int
+	// base holds the level that names with a temporary level revert to,
+	// the last one set without a TTL.
+	base map[string]baseLevel
+
+	current atomic.Value // *levelSnapshot
+}
+
+// baseLevel is a level of Levels.named, ok is false if it is unset.
+type baseLevel struct {
+	level zapcore.Level
+	ok    bool
+}
+
+// levelSnapshot is an immutable copy of the levels read when logging.
+type levelSnapshot struct {
+	root  zapcore.Level
+	min   zapcore.Level
+	named map[string]zapcore.Level
+}
+
+// NewLevels creates Levels with the root logger at root.
+func NewLevels(root zapcore.Level) *Levels {
+	lv := &Levels{
+		root:  root,
+		named: make(map[string]zapcore.Level),
+		gen:   make(map[string]int),
+		base:  make(map[string]baseLevel),
+	}
+	lv.publish()
+
+	return lv
+}
+
+// Set sets the level of the named logger, or of the root logger if
+// name is Root. If ttl is positive the level is temporary: after ttl,
+// unless the level is changed again in the meantime, it reverts to the
+// last level set without a TTL, even if other temporary levels were set
+// in between.
+func (lv *Levels) Set(name string, level zapcore.Level, ttl time.Duration) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	if ttl <= 0 {
+		delete(lv.base, name)
+	} else if _, temporary := lv.base[name]; !temporary {
+		l, ok := lv.get(name)
+		lv.base[name] = baseLevel{l, ok}
+	}
+	lv.set(name, level, true)
+	lv.gen[name]++
+	lv.publish()
+
+	if ttl <= 0 {
+		return
+	}
+	gen := lv.gen[name]
+	time.AfterFunc(ttl, func() {
+		lv.mu.Lock()
+		defer lv.mu.Unlock()
+
+		if lv.gen[name] != gen {
+			return
+		}
+		b := lv.base[name]
+		delete(lv.base, name)
+		lv.set(name, b.level, b.ok)
+		lv.publish()
+	})
+}
+
+// Unset makes the named logger follow the root logger again.
+func (lv *Levels) Unset(name string) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	delete(lv.base, name)
+	lv.set(name, 0, false)
+	lv.gen[name]++
+	lv.publish()
+}
+
+// Levels returns the level of the root logger, under Root, and of
+// every named logger with a level of its own.
+func (lv *Levels) Levels() map[string]zapcore.Level {
+	s := lv.snapshot()
+	levels := map[string]zapcore.Level{Root: s.root}
+	for name, l := range s.named {
+		levels[name] = l
+	}
+	return levels
+}
+
+// Enabled reports whether the logger called name logs at level l.
+func (lv *Levels) Enabled(name string, l zapcore.Level) bool {
+	s := lv.snapshot()
+	if len(s.named) > 0 && name != "" {
+		parts := strings.Split(name, ".")
+		for i := len(parts) - 1; i >= 0; i-- {
+			if nl, ok := s.named[parts[i]]; ok {
+				return l >= nl
+			}
+		}
+	}
+	return l >= s.root
+}
+
+// Core wraps c so it only writes the entries enabled by lv.
+func (lv *Levels) Core(c zapcore.Core) zapcore.Core {
+	return &levelCore{Core: c, lv: lv}
+}
+
+// ServeHTTP lists the levels as JSON on GET and changes one on PUT,
+// e.g. PUT ?logger=storage&level=debug&ttl=15m. An empty logger
+// changes the root level and an empty level unsets a named one.
+func (lv *Levels) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		q := r.URL.Query()
+		name := q.Get("logger")
+		var ttl time.Duration
+		if s := q.Get("ttl"); s != "" {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid ttl %q", s), http.StatusBadRequest)
+				return
+			}
+			ttl = d
+		}
+		if q.Get("level") == "" && name != Root {
+			lv.Unset(name)
+			break
+		}
+		l, err := ParseLevel(q.Get("level"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		lv.Set(name, l, ttl)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	levels := make(map[string]string)
+	for name, l := range lv.Levels() {
+		if name == Root {
+			name = "root"
+		}
+		levels[name] = l.String()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(levels)
+}
+
+func (lv *Levels) get(name string) (zapcore.Level, bool) {
+	if name == Root {
+		return lv.root, true
+	}
+	l, ok := lv.named[name]
+	return l, ok
+}
+
+func (lv *Levels) set(name string, l zapcore.Level, ok bool) {
+	switch {
+	case name == Root:
+		lv.root = l
+	case ok:
+		lv.named[name] = l
+	default:
+		delete(lv.named, name)
+	}
+}
+
+// publish makes the current levels visible to the loggers. It must
+// be called with mu held.
+func (lv *Levels) publish() {
+	s := &levelSnapshot{root: lv.root, min: lv.root, named: make(map[string]zapcore.Level, len(lv.named))}
+	for name, l := range lv.named {
+		s.named[name] = l
+		if l < s.min {
+			s.min = l
+		}
+	}
+	lv.current.Store(s)
+}
+
+func (lv *Levels) snapshot() *levelSnapshot {
+	return lv.current.Load().(*levelSnapshot)
+}
+
+// levelCore filters the entries of a core by the level of the
+// logger that writes them.
+type levelCore struct {
+	zapcore.Core
+	lv *Levels
+}
+
+func (c *levelCore) Enabled(l zapcore.Level) bool {
+	return l >= c.lv.snapshot().min
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), lv: c.lv}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.lv.Enabled(ent.LoggerName, ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// waitLevel waits for the level of name, or for it to be unset if ok is
+// false.
+func waitLevel(t *testing.T, lv *Levels, name string, want zapcore.Level, ok bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		got, set := lv.Levels()[name]
+		if set == ok && (!ok || got == want) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("level of %q = %v (set %v), want %v (set %v)", name, got, set, want, ok)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLevelsTemporaryRevertsToBase(t *testing.T) {
+	lv := NewLevels(zapcore.InfoLevel)
+
+	lv.Set("storage", zapcore.DebugLevel, 200*time.Millisecond)
+	lv.Set("storage", zapcore.WarnLevel, 50*time.Millisecond)
+	waitLevel(t, lv, "storage", zapcore.WarnLevel, true)
+
+	// The second temporary level reverts to the level before the first,
+	// not to the first.
+	waitLevel(t, lv, "storage", 0, false)
+	time.Sleep(250 * time.Millisecond)
+	waitLevel(t, lv, "storage", 0, false)
+}
+
+func TestLevelsTemporaryAfterPermanent(t *testing.T) {
+	lv := NewLevels(zapcore.InfoLevel)
+
+	lv.Set("grpc", zapcore.WarnLevel, 0)
+	lv.Set("grpc", zapcore.DebugLevel, 20*time.Millisecond)
+	waitLevel(t, lv, "grpc", zapcore.WarnLevel, true)
+
+	lv.Set(Root, zapcore.DebugLevel, 20*time.Millisecond)
+	lv.Set(Root, zapcore.ErrorLevel, 0)
+	time.Sleep(50 * time.Millisecond)
+	waitLevel(t, lv, Root, zapcore.ErrorLevel, true)
+}
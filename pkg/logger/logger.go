@@ -1,59 +1,126 @@
 package logger
 
 import (
+	"fmt"
 	"os"
-	"strings"
 	"time"
 
+	zaplogfmt "github.com/jsternberg/zap-logfmt"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-// NewZapLogger creates a new zap Logger.
-func NewZapLogger(lvl int, timeFormat string, appEnv string) *zap.Logger {
-	// First, define our level-handling logic.
-	globalLevel := zapcore.Level(lvl)
-	// High-priority output should also go to standard error, and low-priority
-	// output should also go to standard out.
-	// It is usefull for Kubernetes deployment.
-	// Kubernetes interprets os.Stdout log items as INFO and os.Stderr log items
-	// as ERROR by default.
-	highPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl >= zapcore.ErrorLevel
-	})
-	lowPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl >= globalLevel && lvl < zapcore.ErrorLevel
-	})
-
-	// High-priority output should also go to standard error, and low-priority
-	// output should also go to standard out.
-	consoleDebugging := zapcore.Lock(os.Stdout)
-	consoleErrors := zapcore.Lock(os.Stderr)
-
-	// Optimize the console output for for human operators.
-	eCfg := zap.NewProductionEncoderConfig()
+// Options configures the output of NewZapLogger.
+type Options struct {
+	// Format is console, json or logfmt. It defaults to json in the
+	// production or prod app environment and to console elsewhere.
+	Format     string
+	TimeFormat string
+
+	// Each second the first SampleInitial entries with the same level and
+	// message are logged, then every SampleThereafter-th. 0 disables sampling,
+	// otherwise SampleThereafter must be at least 1.
+	SampleInitial    int
+	SampleThereafter int
+
+	// Caller adds the file and line of the log call to entries.
+	Caller bool
+	// StacktraceLevel adds stack traces to entries at or above it.
+	// Empty disables stack traces.
+	StacktraceLevel string
+
+	// File makes the logs go to a file instead of stdout and stderr. It
+	// is rotated when it grows over MaxSizeMB, keeping up to MaxBackups
+	// old files for up to MaxAgeDays, gzipped if Compress is set.
+	File       string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	Compress   bool
+}
+
+// NewZapLogger creates a new zap Logger. The levels of it and of the
+// loggers named after it can be changed while they are in use through levels.
+func NewZapLogger(levels *Levels, appEnv string, opts Options) (*zap.Logger, error) {
 	// customTimeEncoder encode Time to our custom format
 	// This example how we can customize zap default functionality
+	eCfg := zap.NewProductionEncoderConfig()
 	customTimeEncoder := func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
-		enc.AppendString(t.Format(timeFormat))
+		enc.AppendString(t.Format(opts.TimeFormat))
 	}
 	eCfg.EncodeTime = customTimeEncoder
 
-	var consoleEncoder zapcore.Encoder
-	// set consoleEncoder based on our APP_ENV
-	if !strings.Contains(appEnv, "production") || !strings.Contains(appEnv, "prod") {
-		consoleEncoder = zapcore.NewConsoleEncoder(eCfg)
+	format := opts.Format
+	if format == "" {
+		format = "console"
+		if appEnv == "production" || appEnv == "prod" {
+			format = "json"
+		}
+	}
+	var encoder zapcore.Encoder
+	switch format {
+	case "console":
+		encoder = zapcore.NewConsoleEncoder(eCfg)
+	case "json":
+		encoder = zapcore.NewJSONEncoder(eCfg)
+	case "logfmt":
+		encoder = zaplogfmt.NewEncoder(eCfg)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", opts.Format)
+	}
+
+	// Entries below the level of the logger writing them are dropped
+	// by levels, the cores only pick the output.
+	var core zapcore.Core
+	if opts.File != "" {
+		core = zapcore.NewCore(encoder, zapcore.AddSync(&lumberjack.Logger{
+			Filename:   opts.File,
+			MaxSize:    opts.MaxSizeMB,
+			MaxBackups: opts.MaxBackups,
+			MaxAge:     opts.MaxAgeDays,
+			Compress:   opts.Compress,
+		}), zap.LevelEnablerFunc(func(zapcore.Level) bool { return true }))
 	} else {
-		consoleEncoder = zapcore.NewJSONEncoder(eCfg)
+		// High-priority output should also go to standard error, and low-priority
+		// output should also go to standard out.
+		// It is usefull for Kubernetes deployment.
+		// Kubernetes interprets os.Stdout log items as INFO and os.Stderr log items
+		// as ERROR by default.
+		highPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+			return lvl >= zapcore.ErrorLevel
+		})
+		lowPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+			return lvl < zapcore.ErrorLevel
+		})
+
+		// Join the outputs, encoders, and level-handling functions into
+		// zapcore.Cores, then tee them together.
+		core = zapcore.NewTee(
+			zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), highPriority),
+			zapcore.NewCore(encoder, zapcore.Lock(os.Stdout), lowPriority),
+		)
 	}
-	zapcore.NewConsoleEncoder(eCfg)
 
-	// Join the outputs, encoders, and level-handling functions into
-	// zapcore.Cores, then tee the four cores together.
-	core := zapcore.NewTee(
-		zapcore.NewCore(consoleEncoder, consoleErrors, highPriority),
-		zapcore.NewCore(consoleEncoder, consoleDebugging, lowPriority),
-	)
+	if opts.SampleInitial > 0 {
+		// The sampler divides by SampleThereafter.
+		if opts.SampleThereafter < 1 {
+			return nil, fmt.Errorf("log sampling thereafter must be at least 1, got %d", opts.SampleThereafter)
+		}
+		core = zapcore.NewSampler(core, time.Second, opts.SampleInitial, opts.SampleThereafter)
+	}
+
+	var zapOpts []zap.Option
+	if opts.Caller {
+		zapOpts = append(zapOpts, zap.AddCaller())
+	}
+	if opts.StacktraceLevel != "" {
+		l, err := ParseLevel(opts.StacktraceLevel)
+		if err != nil {
+			return nil, err
+		}
+		zapOpts = append(zapOpts, zap.AddStacktrace(l))
+	}
 
-	return zap.New(core)
+	return zap.New(levels.Core(core), zapOpts...), nil
 }
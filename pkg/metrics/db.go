@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const startTimeKey = "metrics:start_time"
+
+// InstrumentDB records the duration of every query made through db,
+// and exports the connection pool stats of the underlying sql.DB.
+func InstrumentDB(reg prometheus.Registerer, db *gorm.DB) error {
+	durations := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of database queries by operation and table.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "table", "status"})
+	if err := reg.Register(durations); err != nil {
+		return err
+	}
+	if err := reg.Register(newDBStatsCollector(db.DB())); err != nil {
+		return err
+	}
+
+	start := func(scope *gorm.Scope) {
+		scope.Set(startTimeKey, time.Now())
+	}
+	observe := func(operation string) func(scope *gorm.Scope) {
+		return func(scope *gorm.Scope) {
+			v, ok := scope.Get(startTimeKey)
+			if !ok {
+				return
+			}
+			status := "ok"
+			if scope.HasError() && !gorm.IsRecordNotFoundError(scope.DB().Error) {
+				status = "error"
+			}
+			durations.WithLabelValues(operation, scope.TableName(), status).
+				Observe(time.Since(v.(time.Time)).Seconds())
+		}
+	}
+
+	cb := db.Callback()
+	cb.Create().Before("gorm:begin_transaction").Register("metrics:before_create", start)
+	cb.Create().After("gorm:commit_or_rollback_transaction").Register("metrics:after_create", observe("create"))
+	cb.Update().Before("gorm:begin_transaction").Register("metrics:before_update", start)
+	cb.Update().After("gorm:commit_or_rollback_transaction").Register("metrics:after_update", observe("update"))
+	cb.Delete().Before("gorm:begin_transaction").Register("metrics:before_delete", start)
+	cb.Delete().After("gorm:commit_or_rollback_transaction").Register("metrics:after_delete", observe("delete"))
+	cb.Query().Before("gorm:query").Register("metrics:before_query", start)
+	cb.Query().After("gorm:after_query").Register("metrics:after_query", observe("query"))
+	cb.RowQuery().Before("gorm:row_query").Register("metrics:before_row_query", start)
+	cb.RowQuery().After("gorm:row_query").Register("metrics:after_row_query", observe("row_query"))
+
+	return nil
+}
+
+// dbStatsCollector exports sql.DBStats of a connection pool.
+type dbStatsCollector struct {
+	db *sql.DB
+
+	maxOpen      *prometheus.Desc
+	open         *prometheus.Desc
+	inUse        *prometheus.Desc
+	idle         *prometheus.Desc
+	waitCount    *prometheus.Desc
+	waitDuration *prometheus.Desc
+}
+
+func newDBStatsCollector(db *sql.DB) *dbStatsCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &dbStatsCollector{
+		db:           db,
+		maxOpen:      desc("max_open_connections", "Maximum number of open connections to the database."),
+		open:         desc("open_connections", "Number of established connections, both in use and idle."),
+		inUse:        desc("in_use_connections", "Number of connections currently in use."),
+		idle:         desc("idle_connections", "Number of idle connections."),
+		waitCount:    desc("wait_count_total", "Total number of connections waited for."),
+		waitDuration: desc("wait_duration_seconds_total", "Total time blocked waiting for a new connection."),
+	}
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpen
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.db.Stats()
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(s.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(s.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(s.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(s.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, s.WaitDuration.Seconds())
+}
@@ -0,0 +1,18 @@
+package metrics
+
+import (
+	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewServerMetrics creates the per-method RPC counters, status codes and
+// latency histograms and registers them with reg. Its interceptors must be
+// installed on the server and InitializeMetrics called once the services
+// are registered.
+func NewServerMetrics(reg prometheus.Registerer) *grpc_prometheus.ServerMetrics {
+	m := grpc_prometheus.NewServerMetrics()
+	m.EnableHandlingTimeHistogram()
+	reg.MustRegister(m)
+
+	return m
+}
@@ -0,0 +1,388 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: pkg/proto/admin.proto
+
+package todo_v1
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	duration "github.com/golang/protobuf/ptypes/duration"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type LogLevel struct {
+	// Name of the logger, empty for the root logger
+	Logger string `protobuf:"bytes,1,opt,name=logger,proto3" json:"logger,omitempty"`
+	// One of debug, info, warn, error, dpanic, panic or fatal
+	Level                string   `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LogLevel) Reset()         { *m = LogLevel{} }
+func (m *LogLevel) String() string { return proto.CompactTextString(m) }
+func (*LogLevel) ProtoMessage()    {}
+func (*LogLevel) Descriptor() ([]byte, []int) {
+	return fileDescriptor_aa88d8a7a3df6ec9, []int{0}
+}
+
+func (m *LogLevel) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogLevel.Unmarshal(m, b)
+}
+func (m *LogLevel) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogLevel.Marshal(b, m, deterministic)
+}
+func (m *LogLevel) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogLevel.Merge(m, src)
+}
+func (m *LogLevel) XXX_Size() int {
+	return xxx_messageInfo_LogLevel.Size(m)
+}
+func (m *LogLevel) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogLevel.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogLevel proto.InternalMessageInfo
+
+func (m *LogLevel) GetLogger() string {
+	if m != nil {
+		return m.Logger
+	}
+	return ""
+}
+
+func (m *LogLevel) GetLevel() string {
+	if m != nil {
+		return m.Level
+	}
+	return ""
+}
+
+type GetLogLevelsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetLogLevelsRequest) Reset()         { *m = GetLogLevelsRequest{} }
+func (m *GetLogLevelsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetLogLevelsRequest) ProtoMessage()    {}
+func (*GetLogLevelsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_aa88d8a7a3df6ec9, []int{1}
+}
+
+func (m *GetLogLevelsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLogLevelsRequest.Unmarshal(m, b)
+}
+func (m *GetLogLevelsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLogLevelsRequest.Marshal(b, m, deterministic)
+}
+func (m *GetLogLevelsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLogLevelsRequest.Merge(m, src)
+}
+func (m *GetLogLevelsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetLogLevelsRequest.Size(m)
+}
+func (m *GetLogLevelsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLogLevelsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLogLevelsRequest proto.InternalMessageInfo
+
+type GetLogLevelsResponse struct {
+	Levels               []*LogLevel `protobuf:"bytes,1,rep,name=levels,proto3" json:"levels,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *GetLogLevelsResponse) Reset()         { *m = GetLogLevelsResponse{} }
+func (m *GetLogLevelsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetLogLevelsResponse) ProtoMessage()    {}
+func (*GetLogLevelsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_aa88d8a7a3df6ec9, []int{2}
+}
+
+func (m *GetLogLevelsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLogLevelsResponse.Unmarshal(m, b)
+}
+func (m *GetLogLevelsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLogLevelsResponse.Marshal(b, m, deterministic)
+}
+func (m *GetLogLevelsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLogLevelsResponse.Merge(m, src)
+}
+func (m *GetLogLevelsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetLogLevelsResponse.Size(m)
+}
+func (m *GetLogLevelsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLogLevelsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLogLevelsResponse proto.InternalMessageInfo
+
+func (m *GetLogLevelsResponse) GetLevels() []*LogLevel {
+	if m != nil {
+		return m.Levels
+	}
+	return nil
+}
+
+type SetLogLevelRequest struct {
+	Logger string `protobuf:"bytes,1,opt,name=logger,proto3" json:"logger,omitempty"`
+	// Empty makes a named logger follow the root logger again
+	Level string `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"`
+	// Restores the previous level after ttl if set
+	Ttl                  *duration.Duration `protobuf:"bytes,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *SetLogLevelRequest) Reset()         { *m = SetLogLevelRequest{} }
+func (m *SetLogLevelRequest) String() string { return proto.CompactTextString(m) }
+func (*SetLogLevelRequest) ProtoMessage()    {}
+func (*SetLogLevelRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_aa88d8a7a3df6ec9, []int{3}
+}
+
+func (m *SetLogLevelRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetLogLevelRequest.Unmarshal(m, b)
+}
+func (m *SetLogLevelRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetLogLevelRequest.Marshal(b, m, deterministic)
+}
+func (m *SetLogLevelRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetLogLevelRequest.Merge(m, src)
+}
+func (m *SetLogLevelRequest) XXX_Size() int {
+	return xxx_messageInfo_SetLogLevelRequest.Size(m)
+}
+func (m *SetLogLevelRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetLogLevelRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetLogLevelRequest proto.InternalMessageInfo
+
+func (m *SetLogLevelRequest) GetLogger() string {
+	if m != nil {
+		return m.Logger
+	}
+	return ""
+}
+
+func (m *SetLogLevelRequest) GetLevel() string {
+	if m != nil {
+		return m.Level
+	}
+	return ""
+}
+
+func (m *SetLogLevelRequest) GetTtl() *duration.Duration {
+	if m != nil {
+		return m.Ttl
+	}
+	return nil
+}
+
+type SetLogLevelResponse struct {
+	Levels               []*LogLevel `protobuf:"bytes,1,rep,name=levels,proto3" json:"levels,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *SetLogLevelResponse) Reset()         { *m = SetLogLevelResponse{} }
+func (m *SetLogLevelResponse) String() string { return proto.CompactTextString(m) }
+func (*SetLogLevelResponse) ProtoMessage()    {}
+func (*SetLogLevelResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_aa88d8a7a3df6ec9, []int{4}
+}
+
+func (m *SetLogLevelResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetLogLevelResponse.Unmarshal(m, b)
+}
+func (m *SetLogLevelResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetLogLevelResponse.Marshal(b, m, deterministic)
+}
+func (m *SetLogLevelResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetLogLevelResponse.Merge(m, src)
+}
+func (m *SetLogLevelResponse) XXX_Size() int {
+	return xxx_messageInfo_SetLogLevelResponse.Size(m)
+}
+func (m *SetLogLevelResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetLogLevelResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetLogLevelResponse proto.InternalMessageInfo
+
+func (m *SetLogLevelResponse) GetLevels() []*LogLevel {
+	if m != nil {
+		return m.Levels
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*LogLevel)(nil), "todo.v1.LogLevel")
+	proto.RegisterType((*GetLogLevelsRequest)(nil), "todo.v1.GetLogLevelsRequest")
+	proto.RegisterType((*GetLogLevelsResponse)(nil), "todo.v1.GetLogLevelsResponse")
+	proto.RegisterType((*SetLogLevelRequest)(nil), "todo.v1.SetLogLevelRequest")
+	proto.RegisterType((*SetLogLevelResponse)(nil), "todo.v1.SetLogLevelResponse")
+}
+
+func init() { proto.RegisterFile("pkg/proto/admin.proto", fileDescriptor_aa88d8a7a3df6ec9) }
+
+var fileDescriptor_aa88d8a7a3df6ec9 = []byte{
+	// 272 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x90, 0xc1, 0x4e, 0x83, 0x40,
+	0x10, 0x86, 0x83, 0x44, 0xd4, 0xa1, 0x17, 0xb7, 0xad, 0x41, 0xac, 0x86, 0x70, 0xc2, 0x98, 0x2c,
+	0x11, 0x2f, 0x1e, 0x6d, 0x62, 0xa2, 0x89, 0x3d, 0xd1, 0x27, 0x68, 0x65, 0xdc, 0x10, 0xd7, 0x0e,
+	0xb2, 0x0b, 0x6f, 0xe4, 0x7b, 0x1a, 0x60, 0xa9, 0x6d, 0x6c, 0x0f, 0xbd, 0xed, 0xcc, 0x7c, 0x33,
+	0xfb, 0xe7, 0x83, 0x71, 0xf1, 0x29, 0xe2, 0xa2, 0x24, 0x4d, 0xf1, 0x22, 0xfb, 0xca, 0x57, 0xbc,
+	0x7d, 0xb3, 0x13, 0x4d, 0x19, 0xf1, 0xfa, 0xde, 0xbf, 0x11, 0x44, 0x42, 0x62, 0x87, 0x2c, 0xab,
+	0x8f, 0x38, 0xab, 0xca, 0x85, 0xce, 0xc9, 0x80, 0xe1, 0x23, 0x9c, 0xce, 0x48, 0xcc, 0xb0, 0x46,
+	0xc9, 0x2e, 0xc0, 0x91, 0x24, 0x04, 0x96, 0x9e, 0x15, 0x58, 0xd1, 0x59, 0x6a, 0x2a, 0x36, 0x82,
+	0x63, 0xd9, 0x00, 0xde, 0x51, 0xdb, 0xee, 0x8a, 0x70, 0x0c, 0xc3, 0x17, 0xd4, 0xfd, 0xb2, 0x4a,
+	0xf1, 0xbb, 0x42, 0xa5, 0xc3, 0x29, 0x8c, 0xb6, 0xdb, 0xaa, 0xa0, 0x95, 0x42, 0x76, 0x0b, 0x4e,
+	0xbb, 0xa7, 0x3c, 0x2b, 0xb0, 0x23, 0x37, 0x39, 0xe7, 0x26, 0x22, 0xef, 0xd9, 0xd4, 0x00, 0x21,
+	0x01, 0x9b, 0xff, 0x9d, 0x30, 0x87, 0x0f, 0x4b, 0xc7, 0xee, 0xc0, 0xd6, 0x5a, 0x7a, 0x76, 0x60,
+	0x45, 0x6e, 0x72, 0xc9, 0x3b, 0x0b, 0xbc, 0xb7, 0xc0, 0x9f, 0x8d, 0x85, 0xb4, 0xa1, 0xc2, 0x27,
+	0x18, 0x6e, 0x7d, 0x78, 0x70, 0xe4, 0xe4, 0xc7, 0x82, 0xc1, 0xb4, 0xf1, 0x3f, 0xc7, 0xb2, 0xce,
+	0xdf, 0x91, 0xbd, 0xc1, 0x60, 0x53, 0x03, 0x9b, 0xac, 0x77, 0x77, 0x48, 0xf3, 0xaf, 0xf7, 0x4c,
+	0x4d, 0x90, 0x57, 0x70, 0x37, 0xf2, 0xb1, 0xab, 0x35, 0xfd, 0x5f, 0x93, 0x3f, 0xd9, 0x3d, 0xec,
+	0x2e, 0x2d, 0x9d, 0xd6, 0xc0, 0xc3, 0xef, 0x00, 0xf5, 0x2c, 0x83, 0x7e, 0x37, 0x02, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// AdminServiceClient is the client API for AdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AdminServiceClient interface {
+	GetLogLevels(ctx context.Context, in *GetLogLevelsRequest, opts ...grpc.CallOption) (*GetLogLevelsResponse, error)
+	SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error)
+}
+
+type adminServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAdminServiceClient(cc *grpc.ClientConn) AdminServiceClient {
+	return &adminServiceClient{cc}
+}
+
+func (c *adminServiceClient) GetLogLevels(ctx context.Context, in *GetLogLevelsRequest, opts ...grpc.CallOption) (*GetLogLevelsResponse, error) {
+	out := new(GetLogLevelsResponse)
+	err := c.cc.Invoke(ctx, "/todo.v1.AdminService/GetLogLevels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error) {
+	out := new(SetLogLevelResponse)
+	err := c.cc.Invoke(ctx, "/todo.v1.AdminService/SetLogLevel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServiceServer is the server API for AdminService service.
+type AdminServiceServer interface {
+	GetLogLevels(context.Context, *GetLogLevelsRequest) (*GetLogLevelsResponse, error)
+	SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error)
+}
+
+// UnimplementedAdminServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedAdminServiceServer struct {
+}
+
+func (*UnimplementedAdminServiceServer) GetLogLevels(ctx context.Context, req *GetLogLevelsRequest) (*GetLogLevelsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLogLevels not implemented")
+}
+func (*UnimplementedAdminServiceServer) SetLogLevel(ctx context.Context, req *SetLogLevelRequest) (*SetLogLevelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
+}
+
+func RegisterAdminServiceServer(s *grpc.Server, srv AdminServiceServer) {
+	s.RegisterService(&_AdminService_serviceDesc, srv)
+}
+
+func _AdminService_GetLogLevels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLogLevelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetLogLevels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.AdminService/GetLogLevels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetLogLevels(ctx, req.(*GetLogLevelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.AdminService/SetLogLevel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).SetLogLevel(ctx, req.(*SetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _AdminService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "todo.v1.AdminService",
+	HandlerType: (*AdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLogLevels",
+			Handler:    _AdminService_GetLogLevels_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _AdminService_SetLogLevel_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/proto/admin.proto",
+}
@@ -8,6 +8,7 @@ import (
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
 	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -26,16 +27,20 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type Todo struct {
-	Id                   int64                `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Title                string               `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Description          string               `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Reminder             *timestamp.Timestamp `protobuf:"bytes,4,opt,name=reminder,proto3" json:"reminder,omitempty"`
-	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt            *timestamp.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	DeletedAt            *timestamp.Timestamp `protobuf:"bytes,7,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
-	XXX_unrecognized     []byte               `json:"-"`
-	XXX_sizecache        int32                `json:"-"`
+	Id          int64                `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title       string               `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description string               `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Reminder    *timestamp.Timestamp `protobuf:"bytes,4,opt,name=reminder,proto3" json:"reminder,omitempty"`
+	CreatedAt   *timestamp.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt   *timestamp.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	DeletedAt   *timestamp.Timestamp `protobuf:"bytes,7,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	// Subject of the principal that created the todo, set by the server
+	OwnerId string `protobuf:"bytes,8,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	// A letter from A, the highest priority, to Z, or empty
+	Priority             string   `protobuf:"bytes,9,opt,name=priority,proto3" json:"priority,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Todo) Reset()         { *m = Todo{} }
@@ -112,8 +117,26 @@ func (m *Todo) GetDeletedAt() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *Todo) GetOwnerId() string {
+	if m != nil {
+		return m.OwnerId
+	}
+	return ""
+}
+
+func (m *Todo) GetPriority() string {
+	if m != nil {
+		return m.Priority
+	}
+	return ""
+}
+
 type CreateRequest struct {
-	Todo                 *Todo    `protobuf:"bytes,1,opt,name=todo,proto3" json:"todo,omitempty"`
+	Todo *Todo `protobuf:"bytes,1,opt,name=todo,proto3" json:"todo,omitempty"`
+	// Makes retries of the request return the response to the first
+	// attempt, for a day by default, instead of repeating it. Also accepted as
+	// idempotency-key metadata.
+	IdempotencyKey       string   `protobuf:"bytes,2,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -151,6 +174,13 @@ func (m *CreateRequest) GetTodo() *Todo {
 	return nil
 }
 
+func (m *CreateRequest) GetIdempotencyKey() string {
+	if m != nil {
+		return m.IdempotencyKey
+	}
+	return ""
+}
+
 type CreateResponse struct {
 	Todo                 *Todo    `protobuf:"bytes,1,opt,name=todo,proto3" json:"todo,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -269,7 +299,11 @@ func (m *ReadResponse) GetTodo() *Todo {
 }
 
 type UpdateRequest struct {
-	Todo                 *Todo    `protobuf:"bytes,1,opt,name=todo,proto3" json:"todo,omitempty"`
+	Todo *Todo `protobuf:"bytes,1,opt,name=todo,proto3" json:"todo,omitempty"`
+	// Makes retries of the request return the response to the first
+	// attempt, for a day by default, instead of repeating it. Also accepted as
+	// idempotency-key metadata.
+	IdempotencyKey       string   `protobuf:"bytes,2,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -307,6 +341,13 @@ func (m *UpdateRequest) GetTodo() *Todo {
 	return nil
 }
 
+func (m *UpdateRequest) GetIdempotencyKey() string {
+	if m != nil {
+		return m.IdempotencyKey
+	}
+	return ""
+}
+
 type UpdateResponse struct {
 	Updated              *Todo    `protobuf:"bytes,1,opt,name=updated,proto3" json:"updated,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -347,7 +388,11 @@ func (m *UpdateResponse) GetUpdated() *Todo {
 }
 
 type DeleteRequest struct {
-	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Makes retries of the request return the response to the first
+	// attempt, for a day by default, instead of repeating it. Also accepted as
+	// idempotency-key metadata.
+	IdempotencyKey       string   `protobuf:"bytes,2,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -385,6 +430,13 @@ func (m *DeleteRequest) GetId() int64 {
 	return 0
 }
 
+func (m *DeleteRequest) GetIdempotencyKey() string {
+	if m != nil {
+		return m.IdempotencyKey
+	}
+	return ""
+}
+
 type DeleteResponse struct {
 	// Contains number of entities have beed deleted
 	Deleted              int64    `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
@@ -495,6 +547,409 @@ func (m *ReadAllResponse) GetTodos() []*Todo {
 	return nil
 }
 
+type SearchRequest struct {
+	// Free-text query matched against the todo title and description
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Maximum number of results to return, defaults to 20
+	PageSize int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Token from a previous SearchResponse to fetch the next page
+	PageToken            string   `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SearchRequest) Reset()         { *m = SearchRequest{} }
+func (m *SearchRequest) String() string { return proto.CompactTextString(m) }
+func (*SearchRequest) ProtoMessage()    {}
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_707fafb41ec58770, []int{11}
+}
+
+func (m *SearchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchRequest.Unmarshal(m, b)
+}
+func (m *SearchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SearchRequest.Marshal(b, m, deterministic)
+}
+func (m *SearchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchRequest.Merge(m, src)
+}
+func (m *SearchRequest) XXX_Size() int {
+	return xxx_messageInfo_SearchRequest.Size(m)
+}
+func (m *SearchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchRequest proto.InternalMessageInfo
+
+func (m *SearchRequest) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *SearchRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *SearchRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+type SearchResult struct {
+	Todo *Todo `protobuf:"bytes,1,opt,name=todo,proto3" json:"todo,omitempty"`
+	// Relevance of the todo to the query, higher is better
+	Rank float32 `protobuf:"fixed32,2,opt,name=rank,proto3" json:"rank,omitempty"`
+	// HTML-escaped title and description with matched terms wrapped in <b></b>
+	TitleSnippet         string   `protobuf:"bytes,3,opt,name=title_snippet,json=titleSnippet,proto3" json:"title_snippet,omitempty"`
+	DescriptionSnippet   string   `protobuf:"bytes,4,opt,name=description_snippet,json=descriptionSnippet,proto3" json:"description_snippet,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SearchResult) Reset()         { *m = SearchResult{} }
+func (m *SearchResult) String() string { return proto.CompactTextString(m) }
+func (*SearchResult) ProtoMessage()    {}
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_707fafb41ec58770, []int{12}
+}
+
+func (m *SearchResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchResult.Unmarshal(m, b)
+}
+func (m *SearchResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SearchResult.Marshal(b, m, deterministic)
+}
+func (m *SearchResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchResult.Merge(m, src)
+}
+func (m *SearchResult) XXX_Size() int {
+	return xxx_messageInfo_SearchResult.Size(m)
+}
+func (m *SearchResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchResult proto.InternalMessageInfo
+
+func (m *SearchResult) GetTodo() *Todo {
+	if m != nil {
+		return m.Todo
+	}
+	return nil
+}
+
+func (m *SearchResult) GetRank() float32 {
+	if m != nil {
+		return m.Rank
+	}
+	return 0
+}
+
+func (m *SearchResult) GetTitleSnippet() string {
+	if m != nil {
+		return m.TitleSnippet
+	}
+	return ""
+}
+
+func (m *SearchResult) GetDescriptionSnippet() string {
+	if m != nil {
+		return m.DescriptionSnippet
+	}
+	return ""
+}
+
+type SearchResponse struct {
+	Results []*SearchResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	// Empty when there are no more results
+	NextPageToken        string   `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SearchResponse) Reset()         { *m = SearchResponse{} }
+func (m *SearchResponse) String() string { return proto.CompactTextString(m) }
+func (*SearchResponse) ProtoMessage()    {}
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_707fafb41ec58770, []int{13}
+}
+
+func (m *SearchResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchResponse.Unmarshal(m, b)
+}
+func (m *SearchResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SearchResponse.Marshal(b, m, deterministic)
+}
+func (m *SearchResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchResponse.Merge(m, src)
+}
+func (m *SearchResponse) XXX_Size() int {
+	return xxx_messageInfo_SearchResponse.Size(m)
+}
+func (m *SearchResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchResponse proto.InternalMessageInfo
+
+func (m *SearchResponse) GetResults() []*SearchResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+func (m *SearchResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+type ImportRequest struct {
+	Todo *Todo `protobuf:"bytes,1,opt,name=todo,proto3" json:"todo,omitempty"`
+	// Line of the todo in the imported file, reported with its errors
+	Line int32 `protobuf:"varint,2,opt,name=line,proto3" json:"line,omitempty"`
+	// Validates the todos without creating them. Only read from the
+	// first message.
+	DryRun               bool     `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImportRequest) Reset()         { *m = ImportRequest{} }
+func (m *ImportRequest) String() string { return proto.CompactTextString(m) }
+func (*ImportRequest) ProtoMessage()    {}
+func (*ImportRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_707fafb41ec58770, []int{14}
+}
+
+func (m *ImportRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportRequest.Unmarshal(m, b)
+}
+func (m *ImportRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportRequest.Marshal(b, m, deterministic)
+}
+func (m *ImportRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportRequest.Merge(m, src)
+}
+func (m *ImportRequest) XXX_Size() int {
+	return xxx_messageInfo_ImportRequest.Size(m)
+}
+func (m *ImportRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportRequest proto.InternalMessageInfo
+
+func (m *ImportRequest) GetTodo() *Todo {
+	if m != nil {
+		return m.Todo
+	}
+	return nil
+}
+
+func (m *ImportRequest) GetLine() int32 {
+	if m != nil {
+		return m.Line
+	}
+	return 0
+}
+
+func (m *ImportRequest) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+type ImportError struct {
+	Line                 int32    `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImportError) Reset()         { *m = ImportError{} }
+func (m *ImportError) String() string { return proto.CompactTextString(m) }
+func (*ImportError) ProtoMessage()    {}
+func (*ImportError) Descriptor() ([]byte, []int) {
+	return fileDescriptor_707fafb41ec58770, []int{15}
+}
+
+func (m *ImportError) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportError.Unmarshal(m, b)
+}
+func (m *ImportError) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportError.Marshal(b, m, deterministic)
+}
+func (m *ImportError) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportError.Merge(m, src)
+}
+func (m *ImportError) XXX_Size() int {
+	return xxx_messageInfo_ImportError.Size(m)
+}
+func (m *ImportError) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportError.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportError proto.InternalMessageInfo
+
+func (m *ImportError) GetLine() int32 {
+	if m != nil {
+		return m.Line
+	}
+	return 0
+}
+
+func (m *ImportError) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type ImportResponse struct {
+	// Number of todos created, or that would be in a dry run
+	Imported int32 `protobuf:"varint,1,opt,name=imported,proto3" json:"imported,omitempty"`
+	// The todos that weren't imported
+	Errors               []*ImportError `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	DryRun               bool           `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ImportResponse) Reset()         { *m = ImportResponse{} }
+func (m *ImportResponse) String() string { return proto.CompactTextString(m) }
+func (*ImportResponse) ProtoMessage()    {}
+func (*ImportResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_707fafb41ec58770, []int{16}
+}
+
+func (m *ImportResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportResponse.Unmarshal(m, b)
+}
+func (m *ImportResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportResponse.Marshal(b, m, deterministic)
+}
+func (m *ImportResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportResponse.Merge(m, src)
+}
+func (m *ImportResponse) XXX_Size() int {
+	return xxx_messageInfo_ImportResponse.Size(m)
+}
+func (m *ImportResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportResponse proto.InternalMessageInfo
+
+func (m *ImportResponse) GetImported() int32 {
+	if m != nil {
+		return m.Imported
+	}
+	return 0
+}
+
+func (m *ImportResponse) GetErrors() []*ImportError {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+func (m *ImportResponse) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+type ExportRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExportRequest) Reset()         { *m = ExportRequest{} }
+func (m *ExportRequest) String() string { return proto.CompactTextString(m) }
+func (*ExportRequest) ProtoMessage()    {}
+func (*ExportRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_707fafb41ec58770, []int{17}
+}
+
+func (m *ExportRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportRequest.Unmarshal(m, b)
+}
+func (m *ExportRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportRequest.Marshal(b, m, deterministic)
+}
+func (m *ExportRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportRequest.Merge(m, src)
+}
+func (m *ExportRequest) XXX_Size() int {
+	return xxx_messageInfo_ExportRequest.Size(m)
+}
+func (m *ExportRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportRequest proto.InternalMessageInfo
+
+type ExportResponse struct {
+	Todo                 *Todo    `protobuf:"bytes,1,opt,name=todo,proto3" json:"todo,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExportResponse) Reset()         { *m = ExportResponse{} }
+func (m *ExportResponse) String() string { return proto.CompactTextString(m) }
+func (*ExportResponse) ProtoMessage()    {}
+func (*ExportResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_707fafb41ec58770, []int{18}
+}
+
+func (m *ExportResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportResponse.Unmarshal(m, b)
+}
+func (m *ExportResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportResponse.Marshal(b, m, deterministic)
+}
+func (m *ExportResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportResponse.Merge(m, src)
+}
+func (m *ExportResponse) XXX_Size() int {
+	return xxx_messageInfo_ExportResponse.Size(m)
+}
+func (m *ExportResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportResponse proto.InternalMessageInfo
+
+func (m *ExportResponse) GetTodo() *Todo {
+	if m != nil {
+		return m.Todo
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Todo)(nil), "todo.v1.Todo")
 	proto.RegisterType((*CreateRequest)(nil), "todo.v1.CreateRequest")
@@ -507,40 +962,78 @@ func init() {
 	proto.RegisterType((*DeleteResponse)(nil), "todo.v1.DeleteResponse")
 	proto.RegisterType((*ReadAllRequest)(nil), "todo.v1.ReadAllRequest")
 	proto.RegisterType((*ReadAllResponse)(nil), "todo.v1.ReadAllResponse")
+	proto.RegisterType((*SearchRequest)(nil), "todo.v1.SearchRequest")
+	proto.RegisterType((*SearchResult)(nil), "todo.v1.SearchResult")
+	proto.RegisterType((*SearchResponse)(nil), "todo.v1.SearchResponse")
+	proto.RegisterType((*ImportRequest)(nil), "todo.v1.ImportRequest")
+	proto.RegisterType((*ImportError)(nil), "todo.v1.ImportError")
+	proto.RegisterType((*ImportResponse)(nil), "todo.v1.ImportResponse")
+	proto.RegisterType((*ExportRequest)(nil), "todo.v1.ExportRequest")
+	proto.RegisterType((*ExportResponse)(nil), "todo.v1.ExportResponse")
 }
 
 func init() { proto.RegisterFile("pkg/proto/todo.proto", fileDescriptor_707fafb41ec58770) }
 
 var fileDescriptor_707fafb41ec58770 = []byte{
-	// 444 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x93, 0xdf, 0x8e, 0x93, 0x40,
-	0x14, 0xc6, 0x03, 0xfd, 0x83, 0x7b, 0xb0, 0x68, 0x26, 0xd5, 0x9d, 0x90, 0x98, 0x45, 0xbc, 0xb0,
-	0xf1, 0x82, 0x66, 0x69, 0xb2, 0x09, 0x89, 0x37, 0x8d, 0x3e, 0x01, 0xae, 0xd7, 0x86, 0xed, 0x1c,
-	0x9b, 0x89, 0xb4, 0x83, 0x30, 0xdd, 0xf7, 0xf2, 0xca, 0xd7, 0x33, 0xcc, 0x1f, 0x16, 0x5a, 0x4d,
-	0xeb, 0x1d, 0x73, 0xce, 0xf7, 0x9b, 0xf9, 0xce, 0x77, 0x5a, 0x98, 0x57, 0x3f, 0xb6, 0xcb, 0xaa,
-	0x16, 0x52, 0x2c, 0xa5, 0x60, 0x22, 0x51, 0x9f, 0xc4, 0x53, 0xdf, 0x8f, 0xb7, 0xe1, 0xcd, 0x56,
-	0x88, 0x6d, 0x89, 0x5a, 0xf1, 0x70, 0xf8, 0xbe, 0x94, 0x7c, 0x87, 0x8d, 0x2c, 0x76, 0x95, 0x56,
-	0xc6, 0xbf, 0x5d, 0x18, 0xdf, 0x0b, 0x26, 0x48, 0x00, 0x2e, 0x67, 0xd4, 0x89, 0x9c, 0xc5, 0x28,
-	0x77, 0x39, 0x23, 0x73, 0x98, 0x48, 0x2e, 0x4b, 0xa4, 0x6e, 0xe4, 0x2c, 0xae, 0x72, 0x7d, 0x20,
-	0x11, 0xf8, 0x0c, 0x9b, 0x4d, 0xcd, 0x2b, 0xc9, 0xc5, 0x9e, 0x8e, 0x54, 0xaf, 0x5f, 0x22, 0x77,
-	0xf0, 0xac, 0xc6, 0x1d, 0xdf, 0x33, 0xac, 0xe9, 0x38, 0x72, 0x16, 0x7e, 0x1a, 0x26, 0xda, 0x44,
-	0x62, 0x4d, 0x24, 0xf7, 0xd6, 0x44, 0xde, 0x69, 0x49, 0x06, 0xb0, 0xa9, 0xb1, 0x90, 0xc8, 0xbe,
-	0x15, 0x92, 0x4e, 0xce, 0x92, 0x57, 0x46, 0xbd, 0x96, 0x2d, 0x7a, 0xa8, 0x98, 0x45, 0xa7, 0xe7,
-	0x51, 0xa3, 0xd6, 0x28, 0xc3, 0x12, 0x0d, 0xea, 0x9d, 0x47, 0x8d, 0x7a, 0x2d, 0xe3, 0x14, 0x66,
-	0x9f, 0x94, 0x85, 0x1c, 0x7f, 0x1e, 0xb0, 0x91, 0xe4, 0x2d, 0x8c, 0xdb, 0xd8, 0x55, 0x86, 0x7e,
-	0x3a, 0x4b, 0xcc, 0x0e, 0x92, 0x36, 0xde, 0x5c, 0xb5, 0xe2, 0x15, 0x04, 0x96, 0x69, 0x2a, 0xb1,
-	0x6f, 0xf0, 0x12, 0xe8, 0x0d, 0xf8, 0x39, 0x16, 0xcc, 0x3e, 0x73, 0xb4, 0xa8, 0xf8, 0x16, 0x9e,
-	0xeb, 0xf6, 0xe5, 0x37, 0xa6, 0x30, 0xfb, 0xaa, 0x22, 0xf8, 0x0f, 0xeb, 0x19, 0x04, 0x96, 0x31,
-	0x0f, 0xbd, 0x07, 0xcf, 0x04, 0xf9, 0x77, 0xce, 0x76, 0xe3, 0x1b, 0x98, 0x7d, 0x56, 0xb1, 0xfd,
-	0x6b, 0x84, 0x0f, 0x10, 0x58, 0x81, 0xb9, 0x9b, 0x82, 0x67, 0x92, 0x36, 0x32, 0x7b, 0x8c, 0x5f,
-	0x42, 0xd0, 0x8e, 0xbb, 0x2e, 0x4b, 0x73, 0x5b, 0x7c, 0x07, 0x2f, 0xba, 0x8a, 0xc1, 0xdf, 0xc1,
-	0xa4, 0xb5, 0xd2, 0x50, 0x27, 0x1a, 0x9d, 0x1a, 0xd3, 0xbd, 0xf4, 0x97, 0x0b, 0x7e, 0x7b, 0xfe,
-	0x82, 0xf5, 0x23, 0xdf, 0x20, 0xc9, 0x60, 0xaa, 0x97, 0x43, 0x5e, 0x77, 0xfa, 0xc1, 0x86, 0xc3,
-	0xeb, 0x93, 0xba, 0x79, 0x2f, 0x83, 0xa9, 0x1e, 0xa0, 0x87, 0x0e, 0x46, 0xee, 0xa1, 0x47, 0x93,
-	0xae, 0x60, 0xdc, 0xba, 0x27, 0xf3, 0x4e, 0xd0, 0x5b, 0x76, 0xf8, 0xea, 0xa8, 0x6a, 0xa0, 0x8f,
-	0xe0, 0x99, 0x91, 0xc9, 0xf5, 0x40, 0xf1, 0x14, 0x4b, 0x48, 0x4f, 0x1b, 0x4f, 0x6e, 0xf5, 0x2a,
-	0x7b, 0x6e, 0x07, 0xbf, 0x87, 0x9e, 0xdb, 0xe1, 0xce, 0x1f, 0xa6, 0xea, 0x3f, 0xb1, 0xfa, 0x13,
-	0x00, 0x00, 0xff, 0xff, 0xa0, 0x08, 0xd6, 0xb1, 0x77, 0x04, 0x00, 0x00,
+	// 915 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0xcd, 0x6e, 0xdb, 0x46,
+	0x10, 0x06, 0x65, 0x89, 0x94, 0x46, 0x26, 0xed, 0x6c, 0x9c, 0x88, 0x65, 0x1a, 0x54, 0x61, 0x80,
+	0xc6, 0x08, 0x0a, 0xb1, 0x76, 0x80, 0x00, 0x69, 0x4e, 0x46, 0x2b, 0xa0, 0x41, 0x0e, 0x6d, 0x29,
+	0xe7, 0x94, 0x02, 0x02, 0xa3, 0xdd, 0xaa, 0x0b, 0x4b, 0x5c, 0x66, 0x77, 0xe5, 0x4a, 0x29, 0x72,
+	0xe9, 0x2b, 0xf4, 0x54, 0xf4, 0xb1, 0xfa, 0x0a, 0x3d, 0xf7, 0x19, 0x0a, 0xee, 0x0f, 0x4d, 0x5a,
+	0x36, 0xec, 0x1e, 0x7a, 0xe3, 0xfc, 0x7d, 0xf3, 0xed, 0x37, 0xbb, 0x43, 0x38, 0x28, 0xce, 0xe6,
+	0x49, 0xc1, 0x99, 0x64, 0x89, 0x64, 0x98, 0x8d, 0xd4, 0x27, 0xf2, 0xd4, 0xf7, 0xf9, 0x51, 0xf4,
+	0xe9, 0x9c, 0xb1, 0xf9, 0x82, 0x24, 0x59, 0x41, 0x93, 0x2c, 0xcf, 0x99, 0xcc, 0x24, 0x65, 0xb9,
+	0xd0, 0x69, 0xd1, 0x67, 0x26, 0xaa, 0xac, 0x77, 0xab, 0x9f, 0x12, 0x49, 0x97, 0x44, 0xc8, 0x6c,
+	0x59, 0xe8, 0x84, 0xf8, 0x9f, 0x16, 0xb4, 0x4f, 0x19, 0x66, 0x28, 0x80, 0x16, 0xc5, 0xa1, 0x33,
+	0x74, 0x0e, 0x77, 0xd2, 0x16, 0xc5, 0xe8, 0x00, 0x3a, 0x92, 0xca, 0x05, 0x09, 0x5b, 0x43, 0xe7,
+	0xb0, 0x97, 0x6a, 0x03, 0x0d, 0xa1, 0x8f, 0x89, 0x98, 0x71, 0x5a, 0x94, 0x5d, 0xc2, 0x1d, 0x15,
+	0xab, 0xbb, 0xd0, 0x73, 0xe8, 0x72, 0xb2, 0xa4, 0x39, 0x26, 0x3c, 0x6c, 0x0f, 0x9d, 0xc3, 0xfe,
+	0x71, 0x34, 0xd2, 0x24, 0x46, 0x96, 0xc4, 0xe8, 0xd4, 0x92, 0x48, 0xab, 0x5c, 0xf4, 0x02, 0x60,
+	0xc6, 0x49, 0x26, 0x09, 0x9e, 0x66, 0x32, 0xec, 0xdc, 0x58, 0xd9, 0x33, 0xd9, 0x27, 0xb2, 0x2c,
+	0x5d, 0x15, 0xd8, 0x96, 0xba, 0x37, 0x97, 0x9a, 0x6c, 0x5d, 0x8a, 0xc9, 0x82, 0x98, 0x52, 0xef,
+	0xe6, 0x52, 0x93, 0x7d, 0x22, 0xd1, 0x27, 0xd0, 0x65, 0xbf, 0xe4, 0x84, 0x4f, 0x29, 0x0e, 0xbb,
+	0x4a, 0x07, 0x4f, 0xd9, 0xaf, 0x30, 0x8a, 0xa0, 0x5b, 0x70, 0xca, 0x38, 0x95, 0x9b, 0xb0, 0xa7,
+	0x42, 0x95, 0x1d, 0xbf, 0x05, 0xff, 0x6b, 0xc5, 0x3c, 0x25, 0xef, 0x57, 0x44, 0x48, 0xf4, 0x08,
+	0xda, 0x92, 0x61, 0xa6, 0xa4, 0xef, 0x1f, 0xfb, 0x23, 0x33, 0xd8, 0x51, 0x39, 0x95, 0x54, 0x85,
+	0xd0, 0x13, 0xd8, 0xa3, 0x98, 0x2c, 0x0b, 0x26, 0x49, 0x3e, 0xdb, 0x4c, 0xcf, 0xc8, 0xc6, 0x4c,
+	0x25, 0xa8, 0xb9, 0x5f, 0x93, 0x4d, 0xfc, 0x0c, 0x02, 0x0b, 0x2e, 0x0a, 0x96, 0x0b, 0x72, 0x0b,
+	0xf4, 0xf8, 0x21, 0xf4, 0x53, 0x92, 0x61, 0xcb, 0xe7, 0xd2, 0x45, 0x88, 0x8f, 0x60, 0x57, 0x87,
+	0x6f, 0x8f, 0xf8, 0x16, 0xfc, 0x37, 0x4a, 0xe2, 0xff, 0xe3, 0x8c, 0x2f, 0x20, 0xb0, 0xe0, 0x86,
+	0xd1, 0x13, 0xf0, 0xcc, 0x44, 0xaf, 0x6e, 0x60, 0xa3, 0xf1, 0xb7, 0xe0, 0x7f, 0xa3, 0xe6, 0x77,
+	0xcd, 0x59, 0x6f, 0x4f, 0xe2, 0x29, 0x04, 0x16, 0xc9, 0x90, 0x08, 0xc1, 0x33, 0x77, 0xc3, 0xe0,
+	0x59, 0x33, 0xde, 0x87, 0xa0, 0x14, 0xf0, 0x64, 0xb1, 0x30, 0x6d, 0xe3, 0xe7, 0xb0, 0x57, 0x79,
+	0x4c, 0xf9, 0x63, 0xe8, 0x94, 0x9c, 0x45, 0xe8, 0x0c, 0x77, 0xb6, 0x4f, 0xa0, 0x63, 0x71, 0x06,
+	0xfe, 0x84, 0x64, 0x7c, 0xf6, 0xb3, 0xe5, 0x7f, 0x00, 0x9d, 0xf7, 0x2b, 0xc2, 0x37, 0xaa, 0x65,
+	0x2f, 0xd5, 0x06, 0x7a, 0x00, 0xbd, 0x22, 0x9b, 0x93, 0xa9, 0xa0, 0x1f, 0xf4, 0xf3, 0xed, 0xa4,
+	0xdd, 0xd2, 0x31, 0xa1, 0x1f, 0x08, 0x7a, 0x08, 0xa0, 0x82, 0x92, 0x9d, 0x11, 0xfb, 0x80, 0x55,
+	0xfa, 0x69, 0xe9, 0x88, 0xff, 0x74, 0x60, 0xd7, 0xf6, 0x10, 0xab, 0xc5, 0xad, 0x46, 0x87, 0xa0,
+	0xcd, 0xb3, 0xfc, 0x4c, 0xb5, 0x6a, 0xa5, 0xea, 0x1b, 0x3d, 0x06, 0x5f, 0x6d, 0x8c, 0xa9, 0xc8,
+	0x69, 0x51, 0x10, 0x69, 0x3a, 0xed, 0x2a, 0xe7, 0x44, 0xfb, 0x50, 0x02, 0x77, 0x6b, 0xab, 0xa3,
+	0x4a, 0x6d, 0xab, 0x54, 0x54, 0x0b, 0x99, 0x82, 0x98, 0x42, 0x50, 0x91, 0xd3, 0xba, 0x25, 0xe0,
+	0x71, 0x45, 0xd4, 0x2a, 0x77, 0xaf, 0x62, 0x58, 0x3f, 0x46, 0x6a, 0xb3, 0xd0, 0xe7, 0xb0, 0x97,
+	0x93, 0xb5, 0x9c, 0xd6, 0x44, 0xd0, 0x23, 0xf6, 0x4b, 0xf7, 0xf7, 0x95, 0x10, 0x53, 0xf0, 0x5f,
+	0x2d, 0x0b, 0xc6, 0xe5, 0x7f, 0xb8, 0xc3, 0x08, 0xda, 0x0b, 0x9a, 0x5b, 0xcd, 0xd5, 0x37, 0x1a,
+	0x80, 0x87, 0xf9, 0x66, 0xca, 0x57, 0x5a, 0xec, 0x6e, 0xea, 0x62, 0xbe, 0x49, 0x57, 0x79, 0xfc,
+	0x12, 0xfa, 0xba, 0xc1, 0x98, 0x73, 0xc6, 0xab, 0x5a, 0xa7, 0x56, 0x1b, 0x82, 0xb7, 0x24, 0x42,
+	0x64, 0x73, 0xbb, 0x85, 0xad, 0x19, 0x0b, 0x08, 0x2c, 0x3b, 0x23, 0x44, 0x04, 0x5d, 0xaa, 0x3c,
+	0xe6, 0x02, 0x76, 0xd2, 0xca, 0x46, 0x5f, 0x80, 0x4b, 0xca, 0x26, 0x22, 0x6c, 0x29, 0x8d, 0x0e,
+	0x2a, 0xf2, 0x35, 0x06, 0xa9, 0xc9, 0xb9, 0x9e, 0xf1, 0x1e, 0xf8, 0xe3, 0x75, 0x4d, 0x92, 0x72,
+	0xdd, 0x8c, 0xd7, 0x0d, 0x16, 0x37, 0x8b, 0x74, 0xfc, 0x47, 0x07, 0xfa, 0xa5, 0x39, 0x21, 0xfc,
+	0x9c, 0xce, 0x08, 0x4a, 0xc1, 0xd5, 0x3b, 0x0b, 0xdd, 0xaf, 0xd2, 0x1b, 0x1b, 0x32, 0x1a, 0x6c,
+	0xf9, 0x75, 0xb7, 0x78, 0xf0, 0xdb, 0x5f, 0x7f, 0xff, 0xde, 0xba, 0x13, 0xf7, 0x92, 0xf3, 0x23,
+	0xf5, 0x73, 0x14, 0x5f, 0xe9, 0x41, 0xfc, 0x00, 0xae, 0x7e, 0x9e, 0x35, 0xcc, 0xc6, 0xcb, 0x8f,
+	0x06, 0x5b, 0x7e, 0x83, 0x79, 0x5f, 0x61, 0xee, 0x3f, 0x0d, 0x2a, 0xcc, 0xe4, 0x57, 0x8a, 0x3f,
+	0xa2, 0xd7, 0xd0, 0x2e, 0xdf, 0x2c, 0xba, 0xd0, 0xae, 0xb6, 0x34, 0xa3, 0x7b, 0x97, 0xbc, 0x4d,
+	0x30, 0x74, 0x19, 0xec, 0x3b, 0xf0, 0xcc, 0x02, 0x40, 0x83, 0x46, 0xe5, 0xc5, 0x92, 0x88, 0xc2,
+	0xed, 0x80, 0x41, 0xbd, 0xa3, 0x50, 0xfb, 0xe8, 0xe2, 0xd8, 0x68, 0x02, 0xae, 0xbe, 0xee, 0xb5,
+	0x03, 0x37, 0x56, 0x45, 0x34, 0xd8, 0xf2, 0x1b, 0xb4, 0x50, 0xa1, 0x21, 0xb4, 0x7f, 0x21, 0xa2,
+	0xd0, 0x50, 0x3f, 0x82, 0xab, 0x37, 0x6d, 0x0d, 0xb4, 0xb1, 0xd7, 0xa3, 0xc1, 0x96, 0xdf, 0x80,
+	0x3e, 0x52, 0xa0, 0x0f, 0x8e, 0xef, 0xd6, 0x0e, 0xae, 0x52, 0x29, 0xfe, 0x68, 0x66, 0xf4, 0x12,
+	0x5c, 0x7d, 0xfb, 0x6a, 0xe8, 0x8d, 0x17, 0x17, 0x0d, 0xb6, 0xfc, 0x1a, 0xfd, 0xd0, 0x41, 0x6f,
+	0xc0, 0x1d, 0xaf, 0x2f, 0x15, 0x8f, 0xd7, 0x57, 0x17, 0x37, 0xaf, 0xe8, 0x55, 0xe7, 0x25, 0x2a,
+	0xe3, 0x4b, 0xe7, 0x9d, 0xab, 0x7e, 0xf9, 0xcf, 0xfe, 0x1d, 0x00, 0x1f, 0x27, 0xaf, 0x2b, 0x74,
+	0x09, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -559,7 +1052,13 @@ type TodoServiceClient interface {
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
 	Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (*ReadResponse, error)
 	ReadAll(ctx context.Context, in *ReadAllRequest, opts ...grpc.CallOption) (*ReadAllResponse, error)
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
 	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
+	// Import creates the streamed todos, keeping their created_at if set,
+	// and reports the ones that failed.
+	Import(ctx context.Context, opts ...grpc.CallOption) (TodoService_ImportClient, error)
+	// Export streams all the caller's todos.
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (TodoService_ExportClient, error)
 }
 
 type todoServiceClient struct {
@@ -606,6 +1105,15 @@ func (c *todoServiceClient) ReadAll(ctx context.Context, in *ReadAllRequest, opt
 	return out, nil
 }
 
+func (c *todoServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, "/todo.v1.TodoService/Search", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *todoServiceClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error) {
 	out := new(UpdateResponse)
 	err := c.cc.Invoke(ctx, "/todo.v1.TodoService/Update", in, out, opts...)
@@ -615,13 +1123,85 @@ func (c *todoServiceClient) Update(ctx context.Context, in *UpdateRequest, opts
 	return out, nil
 }
 
+func (c *todoServiceClient) Import(ctx context.Context, opts ...grpc.CallOption) (TodoService_ImportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_TodoService_serviceDesc.Streams[0], "/todo.v1.TodoService/Import", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &todoServiceImportClient{stream}
+	return x, nil
+}
+
+type TodoService_ImportClient interface {
+	Send(*ImportRequest) error
+	CloseAndRecv() (*ImportResponse, error)
+	grpc.ClientStream
+}
+
+type todoServiceImportClient struct {
+	grpc.ClientStream
+}
+
+func (x *todoServiceImportClient) Send(m *ImportRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *todoServiceImportClient) CloseAndRecv() (*ImportResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *todoServiceClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (TodoService_ExportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_TodoService_serviceDesc.Streams[1], "/todo.v1.TodoService/Export", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &todoServiceExportClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TodoService_ExportClient interface {
+	Recv() (*ExportResponse, error)
+	grpc.ClientStream
+}
+
+type todoServiceExportClient struct {
+	grpc.ClientStream
+}
+
+func (x *todoServiceExportClient) Recv() (*ExportResponse, error) {
+	m := new(ExportResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // TodoServiceServer is the server API for TodoService service.
 type TodoServiceServer interface {
 	Create(context.Context, *CreateRequest) (*CreateResponse, error)
 	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
 	Read(context.Context, *ReadRequest) (*ReadResponse, error)
 	ReadAll(context.Context, *ReadAllRequest) (*ReadAllResponse, error)
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
 	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
+	// Import creates the streamed todos, keeping their created_at if set,
+	// and reports the ones that failed.
+	Import(TodoService_ImportServer) error
+	// Export streams all the caller's todos.
+	Export(*ExportRequest, TodoService_ExportServer) error
 }
 
 // UnimplementedTodoServiceServer can be embedded to have forward compatible implementations.
@@ -640,9 +1220,18 @@ func (*UnimplementedTodoServiceServer) Read(ctx context.Context, req *ReadReques
 func (*UnimplementedTodoServiceServer) ReadAll(ctx context.Context, req *ReadAllRequest) (*ReadAllResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReadAll not implemented")
 }
+func (*UnimplementedTodoServiceServer) Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
 func (*UnimplementedTodoServiceServer) Update(ctx context.Context, req *UpdateRequest) (*UpdateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
 }
+func (*UnimplementedTodoServiceServer) Import(srv TodoService_ImportServer) error {
+	return status.Errorf(codes.Unimplemented, "method Import not implemented")
+}
+func (*UnimplementedTodoServiceServer) Export(req *ExportRequest, srv TodoService_ExportServer) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
 
 func RegisterTodoServiceServer(s *grpc.Server, srv TodoServiceServer) {
 	s.RegisterService(&_TodoService_serviceDesc, srv)
@@ -720,6 +1309,24 @@ func _TodoService_ReadAll_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TodoService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.TodoService/Search",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TodoService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UpdateRequest)
 	if err := dec(in); err != nil {
@@ -738,6 +1345,53 @@ func _TodoService_Update_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TodoService_Import_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TodoServiceServer).Import(&todoServiceImportServer{stream})
+}
+
+type TodoService_ImportServer interface {
+	SendAndClose(*ImportResponse) error
+	Recv() (*ImportRequest, error)
+	grpc.ServerStream
+}
+
+type todoServiceImportServer struct {
+	grpc.ServerStream
+}
+
+func (x *todoServiceImportServer) SendAndClose(m *ImportResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *todoServiceImportServer) Recv() (*ImportRequest, error) {
+	m := new(ImportRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _TodoService_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TodoServiceServer).Export(m, &todoServiceExportServer{stream})
+}
+
+type TodoService_ExportServer interface {
+	Send(*ExportResponse) error
+	grpc.ServerStream
+}
+
+type todoServiceExportServer struct {
+	grpc.ServerStream
+}
+
+func (x *todoServiceExportServer) Send(m *ExportResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _TodoService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "todo.v1.TodoService",
 	HandlerType: (*TodoServiceServer)(nil),
@@ -758,11 +1412,26 @@ var _TodoService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ReadAll",
 			Handler:    _TodoService_ReadAll_Handler,
 		},
+		{
+			MethodName: "Search",
+			Handler:    _TodoService_Search_Handler,
+		},
 		{
 			MethodName: "Update",
 			Handler:    _TodoService_Update_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Import",
+			Handler:       _TodoService_Import_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Export",
+			Handler:       _TodoService_Export_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "pkg/proto/todo.proto",
 }
@@ -0,0 +1,823 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: pkg/proto/webhook.proto
+
+package todo_v1
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// Webhook is an endpoint that todo events of its owner are posted to.
+type Webhook struct {
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The http or https URL events are posted to, which must not resolve to
+	// a loopback, private, link-local or unspecified address. Redirects are
+	// not followed.
+	Url string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// The events posted: todo.created, todo.updated, todo.deleted and
+	// todo.reminder. All of them if empty.
+	Events []string `protobuf:"bytes,3,rep,name=events,proto3" json:"events,omitempty"`
+	// Key of the HMAC-SHA256 signature of the payloads. Generated if empty
+	// on create, and only returned then.
+	Secret               string               `protobuf:"bytes,4,opt,name=secret,proto3" json:"secret,omitempty"`
+	OwnerId              string               `protobuf:"bytes,5,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Webhook) Reset()         { *m = Webhook{} }
+func (m *Webhook) String() string { return proto.CompactTextString(m) }
+func (*Webhook) ProtoMessage()    {}
+func (*Webhook) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bcd30adb8e468c19, []int{0}
+}
+
+func (m *Webhook) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Webhook.Unmarshal(m, b)
+}
+func (m *Webhook) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Webhook.Marshal(b, m, deterministic)
+}
+func (m *Webhook) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Webhook.Merge(m, src)
+}
+func (m *Webhook) XXX_Size() int {
+	return xxx_messageInfo_Webhook.Size(m)
+}
+func (m *Webhook) XXX_DiscardUnknown() {
+	xxx_messageInfo_Webhook.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Webhook proto.InternalMessageInfo
+
+func (m *Webhook) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Webhook) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Webhook) GetEvents() []string {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func (m *Webhook) GetSecret() string {
+	if m != nil {
+		return m.Secret
+	}
+	return ""
+}
+
+func (m *Webhook) GetOwnerId() string {
+	if m != nil {
+		return m.OwnerId
+	}
+	return ""
+}
+
+func (m *Webhook) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+type CreateWebhookRequest struct {
+	Webhook              *Webhook `protobuf:"bytes,1,opt,name=webhook,proto3" json:"webhook,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateWebhookRequest) Reset()         { *m = CreateWebhookRequest{} }
+func (m *CreateWebhookRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateWebhookRequest) ProtoMessage()    {}
+func (*CreateWebhookRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bcd30adb8e468c19, []int{1}
+}
+
+func (m *CreateWebhookRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateWebhookRequest.Unmarshal(m, b)
+}
+func (m *CreateWebhookRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateWebhookRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateWebhookRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateWebhookRequest.Merge(m, src)
+}
+func (m *CreateWebhookRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateWebhookRequest.Size(m)
+}
+func (m *CreateWebhookRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateWebhookRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateWebhookRequest proto.InternalMessageInfo
+
+func (m *CreateWebhookRequest) GetWebhook() *Webhook {
+	if m != nil {
+		return m.Webhook
+	}
+	return nil
+}
+
+type CreateWebhookResponse struct {
+	Webhook              *Webhook `protobuf:"bytes,1,opt,name=webhook,proto3" json:"webhook,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateWebhookResponse) Reset()         { *m = CreateWebhookResponse{} }
+func (m *CreateWebhookResponse) String() string { return proto.CompactTextString(m) }
+func (*CreateWebhookResponse) ProtoMessage()    {}
+func (*CreateWebhookResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bcd30adb8e468c19, []int{2}
+}
+
+func (m *CreateWebhookResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateWebhookResponse.Unmarshal(m, b)
+}
+func (m *CreateWebhookResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateWebhookResponse.Marshal(b, m, deterministic)
+}
+func (m *CreateWebhookResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateWebhookResponse.Merge(m, src)
+}
+func (m *CreateWebhookResponse) XXX_Size() int {
+	return xxx_messageInfo_CreateWebhookResponse.Size(m)
+}
+func (m *CreateWebhookResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateWebhookResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateWebhookResponse proto.InternalMessageInfo
+
+func (m *CreateWebhookResponse) GetWebhook() *Webhook {
+	if m != nil {
+		return m.Webhook
+	}
+	return nil
+}
+
+type ListWebhooksRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListWebhooksRequest) Reset()         { *m = ListWebhooksRequest{} }
+func (m *ListWebhooksRequest) String() string { return proto.CompactTextString(m) }
+func (*ListWebhooksRequest) ProtoMessage()    {}
+func (*ListWebhooksRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bcd30adb8e468c19, []int{3}
+}
+
+func (m *ListWebhooksRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListWebhooksRequest.Unmarshal(m, b)
+}
+func (m *ListWebhooksRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListWebhooksRequest.Marshal(b, m, deterministic)
+}
+func (m *ListWebhooksRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListWebhooksRequest.Merge(m, src)
+}
+func (m *ListWebhooksRequest) XXX_Size() int {
+	return xxx_messageInfo_ListWebhooksRequest.Size(m)
+}
+func (m *ListWebhooksRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListWebhooksRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListWebhooksRequest proto.InternalMessageInfo
+
+type ListWebhooksResponse struct {
+	Webhooks             []*Webhook `protobuf:"bytes,1,rep,name=webhooks,proto3" json:"webhooks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ListWebhooksResponse) Reset()         { *m = ListWebhooksResponse{} }
+func (m *ListWebhooksResponse) String() string { return proto.CompactTextString(m) }
+func (*ListWebhooksResponse) ProtoMessage()    {}
+func (*ListWebhooksResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bcd30adb8e468c19, []int{4}
+}
+
+func (m *ListWebhooksResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListWebhooksResponse.Unmarshal(m, b)
+}
+func (m *ListWebhooksResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListWebhooksResponse.Marshal(b, m, deterministic)
+}
+func (m *ListWebhooksResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListWebhooksResponse.Merge(m, src)
+}
+func (m *ListWebhooksResponse) XXX_Size() int {
+	return xxx_messageInfo_ListWebhooksResponse.Size(m)
+}
+func (m *ListWebhooksResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListWebhooksResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListWebhooksResponse proto.InternalMessageInfo
+
+func (m *ListWebhooksResponse) GetWebhooks() []*Webhook {
+	if m != nil {
+		return m.Webhooks
+	}
+	return nil
+}
+
+type DeleteWebhookRequest struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteWebhookRequest) Reset()         { *m = DeleteWebhookRequest{} }
+func (m *DeleteWebhookRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteWebhookRequest) ProtoMessage()    {}
+func (*DeleteWebhookRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bcd30adb8e468c19, []int{5}
+}
+
+func (m *DeleteWebhookRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteWebhookRequest.Unmarshal(m, b)
+}
+func (m *DeleteWebhookRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteWebhookRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteWebhookRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteWebhookRequest.Merge(m, src)
+}
+func (m *DeleteWebhookRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteWebhookRequest.Size(m)
+}
+func (m *DeleteWebhookRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteWebhookRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteWebhookRequest proto.InternalMessageInfo
+
+func (m *DeleteWebhookRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type DeleteWebhookResponse struct {
+	Deleted              int64    `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteWebhookResponse) Reset()         { *m = DeleteWebhookResponse{} }
+func (m *DeleteWebhookResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteWebhookResponse) ProtoMessage()    {}
+func (*DeleteWebhookResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bcd30adb8e468c19, []int{6}
+}
+
+func (m *DeleteWebhookResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteWebhookResponse.Unmarshal(m, b)
+}
+func (m *DeleteWebhookResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteWebhookResponse.Marshal(b, m, deterministic)
+}
+func (m *DeleteWebhookResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteWebhookResponse.Merge(m, src)
+}
+func (m *DeleteWebhookResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteWebhookResponse.Size(m)
+}
+func (m *DeleteWebhookResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteWebhookResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteWebhookResponse proto.InternalMessageInfo
+
+func (m *DeleteWebhookResponse) GetDeleted() int64 {
+	if m != nil {
+		return m.Deleted
+	}
+	return 0
+}
+
+// Delivery is an event posted, or to be posted, to a webhook.
+type Delivery struct {
+	Id        int64  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	WebhookId int64  `protobuf:"varint,2,opt,name=webhook_id,json=webhookId,proto3" json:"webhook_id,omitempty"`
+	EventId   string `protobuf:"bytes,3,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Event     string `protobuf:"bytes,4,opt,name=event,proto3" json:"event,omitempty"`
+	// pending until the endpoint answers with a 2xx status, succeeded
+	// then, or dead after the last attempt failed
+	Status   string `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Attempts int32  `protobuf:"varint,6,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	// HTTP status of the last attempt, 0 if it got no response
+	ResponseCode int32 `protobuf:"varint,7,opt,name=response_code,json=responseCode,proto3" json:"response_code,omitempty"`
+	// The status of the last response, or why the last attempt got none
+	LastError            string               `protobuf:"bytes,8,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	NextAttemptAt        *timestamp.Timestamp `protobuf:"bytes,9,opt,name=next_attempt_at,json=nextAttemptAt,proto3" json:"next_attempt_at,omitempty"`
+	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt            *timestamp.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Delivery) Reset()         { *m = Delivery{} }
+func (m *Delivery) String() string { return proto.CompactTextString(m) }
+func (*Delivery) ProtoMessage()    {}
+func (*Delivery) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bcd30adb8e468c19, []int{7}
+}
+
+func (m *Delivery) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Delivery.Unmarshal(m, b)
+}
+func (m *Delivery) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Delivery.Marshal(b, m, deterministic)
+}
+func (m *Delivery) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Delivery.Merge(m, src)
+}
+func (m *Delivery) XXX_Size() int {
+	return xxx_messageInfo_Delivery.Size(m)
+}
+func (m *Delivery) XXX_DiscardUnknown() {
+	xxx_messageInfo_Delivery.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Delivery proto.InternalMessageInfo
+
+func (m *Delivery) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Delivery) GetWebhookId() int64 {
+	if m != nil {
+		return m.WebhookId
+	}
+	return 0
+}
+
+func (m *Delivery) GetEventId() string {
+	if m != nil {
+		return m.EventId
+	}
+	return ""
+}
+
+func (m *Delivery) GetEvent() string {
+	if m != nil {
+		return m.Event
+	}
+	return ""
+}
+
+func (m *Delivery) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *Delivery) GetAttempts() int32 {
+	if m != nil {
+		return m.Attempts
+	}
+	return 0
+}
+
+func (m *Delivery) GetResponseCode() int32 {
+	if m != nil {
+		return m.ResponseCode
+	}
+	return 0
+}
+
+func (m *Delivery) GetLastError() string {
+	if m != nil {
+		return m.LastError
+	}
+	return ""
+}
+
+func (m *Delivery) GetNextAttemptAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.NextAttemptAt
+	}
+	return nil
+}
+
+func (m *Delivery) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+func (m *Delivery) GetUpdatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.UpdatedAt
+	}
+	return nil
+}
+
+type ListDeliveriesRequest struct {
+	WebhookId int64 `protobuf:"varint,1,opt,name=webhook_id,json=webhookId,proto3" json:"webhook_id,omitempty"`
+	// Only lists the deliveries with this status if set, such as dead for
+	// the dead letters
+	Status               string   `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	PageSize             int32    `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken            string   `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListDeliveriesRequest) Reset()         { *m = ListDeliveriesRequest{} }
+func (m *ListDeliveriesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListDeliveriesRequest) ProtoMessage()    {}
+func (*ListDeliveriesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bcd30adb8e468c19, []int{8}
+}
+
+func (m *ListDeliveriesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListDeliveriesRequest.Unmarshal(m, b)
+}
+func (m *ListDeliveriesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListDeliveriesRequest.Marshal(b, m, deterministic)
+}
+func (m *ListDeliveriesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListDeliveriesRequest.Merge(m, src)
+}
+func (m *ListDeliveriesRequest) XXX_Size() int {
+	return xxx_messageInfo_ListDeliveriesRequest.Size(m)
+}
+func (m *ListDeliveriesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListDeliveriesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListDeliveriesRequest proto.InternalMessageInfo
+
+func (m *ListDeliveriesRequest) GetWebhookId() int64 {
+	if m != nil {
+		return m.WebhookId
+	}
+	return 0
+}
+
+func (m *ListDeliveriesRequest) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *ListDeliveriesRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListDeliveriesRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+type ListDeliveriesResponse struct {
+	// Most recent first
+	Deliveries           []*Delivery `protobuf:"bytes,1,rep,name=deliveries,proto3" json:"deliveries,omitempty"`
+	NextPageToken        string      `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ListDeliveriesResponse) Reset()         { *m = ListDeliveriesResponse{} }
+func (m *ListDeliveriesResponse) String() string { return proto.CompactTextString(m) }
+func (*ListDeliveriesResponse) ProtoMessage()    {}
+func (*ListDeliveriesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bcd30adb8e468c19, []int{9}
+}
+
+func (m *ListDeliveriesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListDeliveriesResponse.Unmarshal(m, b)
+}
+func (m *ListDeliveriesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListDeliveriesResponse.Marshal(b, m, deterministic)
+}
+func (m *ListDeliveriesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListDeliveriesResponse.Merge(m, src)
+}
+func (m *ListDeliveriesResponse) XXX_Size() int {
+	return xxx_messageInfo_ListDeliveriesResponse.Size(m)
+}
+func (m *ListDeliveriesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListDeliveriesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListDeliveriesResponse proto.InternalMessageInfo
+
+func (m *ListDeliveriesResponse) GetDeliveries() []*Delivery {
+	if m != nil {
+		return m.Deliveries
+	}
+	return nil
+}
+
+func (m *ListDeliveriesResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*Webhook)(nil), "todo.v1.Webhook")
+	proto.RegisterType((*CreateWebhookRequest)(nil), "todo.v1.CreateWebhookRequest")
+	proto.RegisterType((*CreateWebhookResponse)(nil), "todo.v1.CreateWebhookResponse")
+	proto.RegisterType((*ListWebhooksRequest)(nil), "todo.v1.ListWebhooksRequest")
+	proto.RegisterType((*ListWebhooksResponse)(nil), "todo.v1.ListWebhooksResponse")
+	proto.RegisterType((*DeleteWebhookRequest)(nil), "todo.v1.DeleteWebhookRequest")
+	proto.RegisterType((*DeleteWebhookResponse)(nil), "todo.v1.DeleteWebhookResponse")
+	proto.RegisterType((*Delivery)(nil), "todo.v1.Delivery")
+	proto.RegisterType((*ListDeliveriesRequest)(nil), "todo.v1.ListDeliveriesRequest")
+	proto.RegisterType((*ListDeliveriesResponse)(nil), "todo.v1.ListDeliveriesResponse")
+}
+
+func init() { proto.RegisterFile("pkg/proto/webhook.proto", fileDescriptor_bcd30adb8e468c19) }
+
+var fileDescriptor_bcd30adb8e468c19 = []byte{
+	// 636 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0x51, 0x6e, 0xd3, 0x40,
+	0x10, 0x95, 0x63, 0xd2, 0xc4, 0xd3, 0xa6, 0x94, 0x25, 0x29, 0xc6, 0xd0, 0x36, 0x32, 0x52, 0x15,
+	0x21, 0xe4, 0xaa, 0xe5, 0x8b, 0xcf, 0xb4, 0xe5, 0xa3, 0x02, 0x55, 0xe0, 0x56, 0xe2, 0x33, 0x72,
+	0xb3, 0x43, 0xb0, 0x9a, 0x66, 0x8d, 0x77, 0x93, 0x42, 0x8f, 0xc0, 0x19, 0xb8, 0x04, 0x37, 0xe1,
+	0x48, 0x68, 0xd7, 0x63, 0x13, 0x3b, 0x89, 0xaa, 0xfe, 0x79, 0x66, 0xde, 0xcc, 0xbe, 0x9d, 0xf7,
+	0xd6, 0xf0, 0x2c, 0xb9, 0x1e, 0x1d, 0x24, 0xa9, 0x50, 0xe2, 0xe0, 0x16, 0xaf, 0xbe, 0x09, 0x71,
+	0x1d, 0x98, 0x88, 0x35, 0x94, 0xe0, 0x22, 0x98, 0x1d, 0x7a, 0x7b, 0x23, 0x21, 0x46, 0x63, 0xcc,
+	0x40, 0x57, 0xd3, 0xaf, 0x07, 0x2a, 0xbe, 0x41, 0xa9, 0xa2, 0x9b, 0x24, 0x43, 0xfa, 0x7f, 0x2c,
+	0x68, 0x7c, 0xc9, 0x7a, 0xd9, 0x26, 0xd4, 0x62, 0xee, 0x5a, 0x5d, 0xab, 0x67, 0x87, 0xb5, 0x98,
+	0xb3, 0x2d, 0xb0, 0xa7, 0xe9, 0xd8, 0xad, 0x75, 0xad, 0x9e, 0x13, 0xea, 0x4f, 0xb6, 0x0d, 0x6b,
+	0x38, 0xc3, 0x89, 0x92, 0xae, 0xdd, 0xb5, 0x7b, 0x4e, 0x48, 0x91, 0xce, 0x4b, 0x1c, 0xa6, 0xa8,
+	0xdc, 0x47, 0x06, 0x4c, 0x11, 0x7b, 0x0e, 0x4d, 0x71, 0x3b, 0xc1, 0x74, 0x10, 0x73, 0xb7, 0x6e,
+	0x2a, 0x0d, 0x13, 0x9f, 0x71, 0xf6, 0x0e, 0x60, 0x98, 0x62, 0xa4, 0x90, 0x0f, 0x22, 0xe5, 0xae,
+	0x75, 0xad, 0xde, 0xfa, 0x91, 0x17, 0x64, 0x74, 0x83, 0x9c, 0x6e, 0x70, 0x99, 0xd3, 0x0d, 0x1d,
+	0x42, 0xf7, 0x95, 0x7f, 0x0c, 0xed, 0x13, 0x13, 0x10, 0xf1, 0x10, 0xbf, 0x4f, 0x51, 0x2a, 0xf6,
+	0x1a, 0x1a, 0xb4, 0x06, 0x73, 0x89, 0xf5, 0xa3, 0xad, 0x80, 0xf6, 0x10, 0xe4, 0xc8, 0x1c, 0xe0,
+	0x9f, 0x40, 0xa7, 0x32, 0x43, 0x26, 0x62, 0x22, 0xf1, 0x41, 0x43, 0x3a, 0xf0, 0xf4, 0x63, 0x2c,
+	0x15, 0xe5, 0x25, 0xf1, 0xf0, 0x4f, 0xa1, 0x5d, 0x4e, 0xd3, 0xe8, 0x37, 0xd0, 0xa4, 0x4e, 0xe9,
+	0x5a, 0x5d, 0x7b, 0xe9, 0xec, 0x02, 0xe1, 0xef, 0x43, 0xfb, 0x14, 0xc7, 0xb8, 0x70, 0xcb, 0x8a,
+	0x4a, 0xfe, 0x21, 0x74, 0x2a, 0x38, 0x3a, 0xce, 0x85, 0x06, 0x37, 0x85, 0x1c, 0x9d, 0x87, 0xfe,
+	0x6f, 0x1b, 0x9a, 0xa7, 0x38, 0x8e, 0x67, 0x98, 0xfe, 0x5c, 0x50, 0x7d, 0x07, 0x80, 0x38, 0x68,
+	0xd5, 0x6a, 0x26, 0xef, 0x50, 0xe6, 0x8c, 0x6b, 0x49, 0x8d, 0xe8, 0xba, 0x68, 0x67, 0x92, 0x9a,
+	0xf8, 0x8c, 0xb3, 0x36, 0xd4, 0xcd, 0x27, 0x99, 0x20, 0x0b, 0x8c, 0x37, 0x54, 0xa4, 0xa6, 0x92,
+	0x1c, 0x40, 0x11, 0xf3, 0xa0, 0x19, 0x29, 0x85, 0x37, 0x89, 0x92, 0x46, 0xfe, 0x7a, 0x58, 0xc4,
+	0xec, 0x15, 0xb4, 0x52, 0xba, 0xc6, 0x60, 0x28, 0x38, 0xba, 0x0d, 0x03, 0xd8, 0xc8, 0x93, 0x27,
+	0x82, 0xa3, 0x26, 0x3a, 0x8e, 0xa4, 0x1a, 0x60, 0x9a, 0x8a, 0xd4, 0x6d, 0x9a, 0xe1, 0x8e, 0xce,
+	0xbc, 0xd7, 0x09, 0x76, 0x0c, 0x8f, 0x27, 0xf8, 0x43, 0x0d, 0x68, 0xa8, 0x76, 0x99, 0x73, 0xaf,
+	0xcb, 0x5a, 0xba, 0xa5, 0x9f, 0x75, 0xf4, 0x55, 0xc5, 0xa4, 0xf0, 0x00, 0x93, 0xea, 0xd6, 0x69,
+	0xc2, 0xf3, 0xd6, 0xf5, 0xfb, 0x5b, 0x09, 0xdd, 0x57, 0xfe, 0x2f, 0x0b, 0x3a, 0xda, 0x40, 0x24,
+	0x51, 0x8c, 0xb9, 0xb3, 0x2a, 0xda, 0x58, 0x55, 0x6d, 0xfe, 0xaf, 0xba, 0x56, 0x5a, 0xf5, 0x0b,
+	0x70, 0x92, 0x68, 0x84, 0x03, 0x19, 0xdf, 0xa1, 0x11, 0xad, 0x1e, 0x36, 0x75, 0xe2, 0x22, 0xbe,
+	0x33, 0x6b, 0x34, 0x45, 0x25, 0xae, 0x71, 0x42, 0xd2, 0x19, 0xf8, 0xa5, 0x4e, 0xf8, 0x12, 0xb6,
+	0xab, 0x5c, 0xc8, 0x5f, 0x87, 0x00, 0xbc, 0xc8, 0x92, 0xa1, 0x9f, 0x14, 0x86, 0xce, 0xfd, 0x15,
+	0xce, 0x81, 0xd8, 0x3e, 0x69, 0x32, 0x77, 0x60, 0xc6, 0xd4, 0xec, 0xfd, 0x53, 0x7e, 0xe8, 0xd1,
+	0xdf, 0x1a, 0x6c, 0x92, 0x9d, 0x2f, 0x30, 0x9d, 0xc5, 0x43, 0x64, 0xe7, 0xd0, 0x2a, 0x3d, 0x58,
+	0xb6, 0x53, 0x1c, 0xb5, 0xec, 0x67, 0xe0, 0xed, 0xae, 0x2a, 0x13, 0xfb, 0x0f, 0xb0, 0x31, 0xff,
+	0x48, 0xd9, 0xcb, 0x02, 0xbf, 0xe4, 0x49, 0x7b, 0x3b, 0x2b, 0xaa, 0x34, 0xec, 0x1c, 0x5a, 0xa5,
+	0x37, 0x38, 0x47, 0x6e, 0xd9, 0x1b, 0xf6, 0x76, 0x57, 0x95, 0x69, 0xde, 0x67, 0xd8, 0x2c, 0x2f,
+	0x9d, 0xed, 0x96, 0x08, 0x2c, 0x38, 0xc3, 0xdb, 0x5b, 0x59, 0xcf, 0x46, 0x5e, 0xad, 0x19, 0xcf,
+	0xbd, 0xfd, 0x37, 0x00, 0x46, 0xbe, 0xd4, 0xa7, 0x34, 0x06, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// WebhookServiceClient is the client API for WebhookService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type WebhookServiceClient interface {
+	CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*CreateWebhookResponse, error)
+	ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error)
+	DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*DeleteWebhookResponse, error)
+	ListDeliveries(ctx context.Context, in *ListDeliveriesRequest, opts ...grpc.CallOption) (*ListDeliveriesResponse, error)
+}
+
+type webhookServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewWebhookServiceClient(cc *grpc.ClientConn) WebhookServiceClient {
+	return &webhookServiceClient{cc}
+}
+
+func (c *webhookServiceClient) CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*CreateWebhookResponse, error) {
+	out := new(CreateWebhookResponse)
+	err := c.cc.Invoke(ctx, "/todo.v1.WebhookService/CreateWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookServiceClient) ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error) {
+	out := new(ListWebhooksResponse)
+	err := c.cc.Invoke(ctx, "/todo.v1.WebhookService/ListWebhooks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookServiceClient) DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*DeleteWebhookResponse, error) {
+	out := new(DeleteWebhookResponse)
+	err := c.cc.Invoke(ctx, "/todo.v1.WebhookService/DeleteWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookServiceClient) ListDeliveries(ctx context.Context, in *ListDeliveriesRequest, opts ...grpc.CallOption) (*ListDeliveriesResponse, error) {
+	out := new(ListDeliveriesResponse)
+	err := c.cc.Invoke(ctx, "/todo.v1.WebhookService/ListDeliveries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WebhookServiceServer is the server API for WebhookService service.
+type WebhookServiceServer interface {
+	CreateWebhook(context.Context, *CreateWebhookRequest) (*CreateWebhookResponse, error)
+	ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error)
+	DeleteWebhook(context.Context, *DeleteWebhookRequest) (*DeleteWebhookResponse, error)
+	ListDeliveries(context.Context, *ListDeliveriesRequest) (*ListDeliveriesResponse, error)
+}
+
+// UnimplementedWebhookServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedWebhookServiceServer struct {
+}
+
+func (*UnimplementedWebhookServiceServer) CreateWebhook(ctx context.Context, req *CreateWebhookRequest) (*CreateWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWebhook not implemented")
+}
+func (*UnimplementedWebhookServiceServer) ListWebhooks(ctx context.Context, req *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhooks not implemented")
+}
+func (*UnimplementedWebhookServiceServer) DeleteWebhook(ctx context.Context, req *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhook not implemented")
+}
+func (*UnimplementedWebhookServiceServer) ListDeliveries(ctx context.Context, req *ListDeliveriesRequest) (*ListDeliveriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeliveries not implemented")
+}
+
+func RegisterWebhookServiceServer(s *grpc.Server, srv WebhookServiceServer) {
+	s.RegisterService(&_WebhookService_serviceDesc, srv)
+}
+
+func _WebhookService_CreateWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).CreateWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.WebhookService/CreateWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).CreateWebhook(ctx, req.(*CreateWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebhookService_ListWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).ListWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.WebhookService/ListWebhooks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).ListWebhooks(ctx, req.(*ListWebhooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebhookService_DeleteWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).DeleteWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.WebhookService/DeleteWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).DeleteWebhook(ctx, req.(*DeleteWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebhookService_ListDeliveries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeliveriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).ListDeliveries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.WebhookService/ListDeliveries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).ListDeliveries(ctx, req.(*ListDeliveriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _WebhookService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "todo.v1.WebhookService",
+	HandlerType: (*WebhookServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateWebhook",
+			Handler:    _WebhookService_CreateWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhooks",
+			Handler:    _WebhookService_ListWebhooks_Handler,
+		},
+		{
+			MethodName: "DeleteWebhook",
+			Handler:    _WebhookService_DeleteWebhook_Handler,
+		},
+		{
+			MethodName: "ListDeliveries",
+			Handler:    _WebhookService_ListDeliveries_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/proto/webhook.proto",
+}
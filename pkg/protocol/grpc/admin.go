@@ -0,0 +1,62 @@
+package grpc
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/logger"
+	pb "github.com/dikaeinstein/prototodo/pkg/proto"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type adminHandler struct {
+	levels *logger.Levels
+}
+
+// NewGRPCAdminHandler creates a new adminHandler
+// which implements the pb.AdminServiceServer interface
+func NewGRPCAdminHandler(levels *logger.Levels) pb.AdminServiceServer {
+	return &adminHandler{levels}
+}
+
+func (h *adminHandler) GetLogLevels(ctx context.Context, req *pb.GetLogLevelsRequest) (*pb.GetLogLevelsResponse, error) {
+	return &pb.GetLogLevelsResponse{Levels: h.logLevels()}, nil
+}
+
+func (h *adminHandler) SetLogLevel(ctx context.Context, req *pb.SetLogLevelRequest) (*pb.SetLogLevelResponse, error) {
+	var ttl time.Duration
+	if req.Ttl != nil {
+		d, err := ptypes.Duration(req.Ttl)
+		if err != nil || d < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid ttl: %v", req.Ttl)
+		}
+		ttl = d
+	}
+
+	if req.Level == "" && req.Logger != logger.Root {
+		h.levels.Unset(req.Logger)
+	} else {
+		l, err := logger.ParseLevel(req.Level)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		h.levels.Set(req.Logger, l, ttl)
+	}
+
+	return &pb.SetLogLevelResponse{Levels: h.logLevels()}, nil
+}
+
+func (h *adminHandler) logLevels() []*pb.LogLevel {
+	var levels []*pb.LogLevel
+	for name, l := range h.levels.Levels() {
+		levels = append(levels, &pb.LogLevel{Logger: name, Level: l.String()})
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].Logger < levels[j].Logger
+	})
+
+	return levels
+}
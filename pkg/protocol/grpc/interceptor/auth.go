@@ -0,0 +1,113 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dikaeinstein/prototodo/pkg/auth"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// publicServices can be called without authenticating.
+var publicServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+// TokenValidator validates bearer tokens and returns the principal they identify.
+type TokenValidator interface {
+	Validate(token string) (auth.Principal, error)
+}
+
+// AuthenticateUnary rejects unary calls without a valid bearer token or
+// verified client certificate and stores the caller's principal in the
+// request context. Bearer tokens are rejected if v is nil.
+func AuthenticateUnary(v TokenValidator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if isPublic(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := authenticate(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthenticateStream rejects streaming calls without a valid bearer token or
+// verified client certificate and stores the caller's principal in the
+// stream context. Bearer tokens are rejected if v is nil.
+func AuthenticateStream(v TokenValidator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isPublic(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		ctx, err := authenticate(ss.Context(), v)
+		if err != nil {
+			return err
+		}
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctx
+		return handler(srv, wrapped)
+	}
+}
+
+// authenticate prefers a bearer token over the client certificate, so a
+// service with a certificate can still act on behalf of a user.
+func authenticate(ctx context.Context, v TokenValidator) (context.Context, error) {
+	token, err := grpc_auth.AuthFromMD(ctx, "bearer")
+	if err != nil {
+		if p, ok := certPrincipal(ctx); ok {
+			return auth.NewContext(ctx, p), nil
+		}
+		return nil, err
+	}
+
+	if v == nil {
+		return nil, status.Error(codes.Unauthenticated, "bearer tokens are not accepted")
+	}
+	p, err := v.Validate(token)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid auth token: %v", err)
+	}
+
+	return auth.NewContext(ctx, p), nil
+}
+
+// certPrincipal returns the principal of the verified client certificate
+// the peer presented during the TLS handshake.
+func certPrincipal(ctx context.Context) (auth.Principal, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return auth.Anonymous, false
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return auth.Anonymous, false
+	}
+	chains := tlsInfo.State.VerifiedChains
+	if len(chains) == 0 || len(chains[0]) == 0 {
+		return auth.Anonymous, false
+	}
+
+	return auth.FromCertificate(chains[0][0]), true
+}
+
+func isPublic(fullMethod string) bool {
+	for _, s := range publicServices {
+		if strings.HasPrefix(fullMethod, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package interceptor
+
+import (
+	"context"
+
+	"github.com/dikaeinstein/prototodo/pkg/auth"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Authorizer decides whether a principal may call a gRPC method.
+type Authorizer interface {
+	Allowed(p auth.Principal, fullMethod string) bool
+}
+
+// AuthorizeUnary rejects unary calls the authenticated principal
+// isn't allowed to make. It must run after AuthenticateUnary.
+func AuthorizeUnary(a Authorizer, l *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authorize(ctx, a, l, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthorizeStream rejects streaming calls the authenticated principal
+// isn't allowed to make. It must run after AuthenticateStream.
+func AuthorizeStream(a Authorizer, l *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), a, l, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func authorize(ctx context.Context, a Authorizer, l *zap.Logger, fullMethod string) error {
+	if isPublic(fullMethod) {
+		return nil
+	}
+
+	p := auth.FromContext(ctx)
+	if !a.Allowed(p, fullMethod) {
+		l.Warn("permission denied",
+			zap.String("principal", p.Subject),
+			zap.Strings("roles", p.Roles),
+			zap.String("grpc.method", fullMethod),
+		)
+		return status.Errorf(codes.PermissionDenied, "%s is not allowed to call %s", p.Subject, fullMethod)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// ForwardedPeerUnary replaces the peer of unary calls with the client
+// address the REST gateway passes in x-forwarded-for, so calls made through
+// the gateway are logged and rate limited per client. It must only be used
+// on servers that are reachable through the gateway alone.
+func ForwardedPeerUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(forwardedPeer(ctx), req)
+	}
+}
+
+// ForwardedPeerStream replaces the peer of streaming calls with the client
+// address the REST gateway passes in x-forwarded-for.
+func ForwardedPeerStream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = forwardedPeer(ss.Context())
+		return handler(srv, wrapped)
+	}
+}
+
+// forwardedPeer uses the last x-forwarded-for address, the one added by
+// the gateway; earlier ones come from the HTTP client and can't be trusted.
+func forwardedPeer(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	fwd := md.Get("x-forwarded-for")
+	if len(fwd) == 0 {
+		return ctx
+	}
+	addrs := strings.Split(fwd[len(fwd)-1], ",")
+	ip := net.ParseIP(strings.TrimSpace(addrs[len(addrs)-1]))
+	if ip == nil {
+		return ctx
+	}
+
+	p := &peer.Peer{Addr: &net.TCPAddr{IP: ip}}
+	if orig, ok := peer.FromContext(ctx); ok {
+		p.AuthInfo = orig.AuthInfo
+	}
+	return peer.NewContext(ctx, p)
+}
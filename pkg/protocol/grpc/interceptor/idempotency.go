@@ -0,0 +1,209 @@
+package interceptor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/logger"
+	pb "github.com/dikaeinstein/prototodo/pkg/proto"
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/go-grpc-middleware/util/metautils"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// IdempotencyKeyHeader is the metadata key an idempotency key can be sent
+// in instead of the idempotency_key field of requests.
+const IdempotencyKeyHeader = "idempotency-key"
+
+// IdempotentReplayedHeader is set in the response header of calls whose
+// response was replayed.
+const IdempotentReplayedHeader = "idempotent-replayed"
+
+const (
+	maxIdempotencyKeyLen = 255
+	// pendingKeyTTL frees the key of a call that never completed, such as
+	// when the server crashed while handling it.
+	pendingKeyTTL = time.Minute
+	// sweepInterval is how often expired keys are deleted.
+	sweepInterval = 10 * time.Minute
+)
+
+// idempotentMethods are the methods that accept an idempotency key, with
+// the responses their replayed responses are decoded into.
+var idempotentMethods = map[string]func() proto.Message{
+	"/todo.v1.TodoService/Create": func() proto.Message { return new(pb.CreateResponse) },
+	"/todo.v1.TodoService/Update": func() proto.Message { return new(pb.UpdateResponse) },
+	"/todo.v1.TodoService/Delete": func() proto.Message { return new(pb.DeleteResponse) },
+}
+
+// IdempotencyStore keeps the idempotency keys of the caller in ctx.
+type IdempotencyStore interface {
+	ReserveIdempotencyKey(ctx context.Context, k todo.IdempotencyKey) (todo.IdempotencyKey, bool, error)
+	CompleteIdempotencyKey(ctx context.Context, key string, response []byte, expiresAt time.Time) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+// Idempotency replays the response to the first successful call made with
+// an idempotency key to later calls with the same key, for ttl.
+type Idempotency struct {
+	store IdempotencyStore
+	ttl   time.Duration
+	l     *zap.Logger
+}
+
+// NewIdempotency creates an Idempotency that keeps responses in store.
+func NewIdempotency(store IdempotencyStore, ttl time.Duration, l *zap.Logger) *Idempotency {
+	return &Idempotency{store: store, ttl: ttl, l: l}
+}
+
+// Run deletes expired keys periodically until stop is closed.
+func (i *Idempotency) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		n, err := i.store.DeleteExpiredIdempotencyKeys(context.Background(), time.Now())
+		if err != nil {
+			i.l.Error("failed to delete expired idempotency keys", zap.Error(err))
+			continue
+		}
+		i.l.Debug("deleted expired idempotency keys", zap.Int64("count", n))
+	}
+}
+
+// IdempotencyUnary handles Create, Update and Delete calls with an
+// idempotency key at most once per caller and key. Retries get the response
+// to the first call, a call reusing the key for another request fails with
+// FailedPrecondition and one made while the first is in progress fails
+// with Aborted. Keys are per principal, so it must run after
+// AuthenticateUnary if auth is enabled.
+func IdempotencyUnary(i *Idempotency) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		newResponse, ok := idempotentMethods[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+		key, err := idempotencyKey(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if key == "" {
+			return handler(ctx, req)
+		}
+		return i.handle(ctx, key, req.(proto.Message), info.FullMethod, newResponse, handler)
+	}
+}
+
+func (i *Idempotency) handle(ctx context.Context, key string, req proto.Message, method string,
+	newResponse func() proto.Message, handler grpc.UnaryHandler) (interface{}, error) {
+	hash, err := requestHash(method, req)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to hash request: %v", err)
+	}
+
+	k, reserved, err := i.store.ReserveIdempotencyKey(ctx, todo.IdempotencyKey{
+		Key:         key,
+		Method:      method,
+		RequestHash: hash,
+		ExpiresAt:   time.Now().Add(pendingKeyTTL),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to reserve idempotency key: %v", err)
+	}
+	if !reserved {
+		return replay(ctx, k, method, hash, newResponse)
+	}
+
+	resp, err := handler(ctx, req)
+	if err != nil {
+		// Only successes are kept, so failed calls can be retried.
+		if rerr := i.store.ReleaseIdempotencyKey(ctx, key); rerr != nil {
+			logger.FromContext(ctx).Error("failed to release idempotency key", zap.Error(rerr))
+		}
+		return nil, err
+	}
+
+	b, err := proto.Marshal(resp.(proto.Message))
+	if err == nil {
+		err = i.store.CompleteIdempotencyKey(ctx, key, b, time.Now().Add(i.ttl))
+	}
+	if err != nil {
+		// The call succeeded, so its response is returned even though
+		// retries of it won't be replayed.
+		logger.FromContext(ctx).Error("failed to store idempotent response", zap.Error(err))
+	}
+
+	return resp, nil
+}
+
+// replay returns the stored response to the call that reserved k, if it
+// was the same request.
+func replay(ctx context.Context, k todo.IdempotencyKey, method string, hash []byte, newResponse func() proto.Message) (interface{}, error) {
+	if k.Method != method || !bytes.Equal(k.RequestHash, hash) {
+		return nil, status.Error(codes.FailedPrecondition, "idempotency key was already used for a different request")
+	}
+	if !k.Completed {
+		return nil, status.Error(codes.Aborted, "a request with this idempotency key is in progress")
+	}
+
+	resp := newResponse()
+	if err := proto.Unmarshal(k.Response, resp); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode stored response: %v", err)
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(IdempotentReplayedHeader, "true"))
+
+	return resp, nil
+}
+
+// idempotencyKey returns the key in the idempotency_key field of req or in
+// the metadata, which must agree if both are set.
+func idempotencyKey(ctx context.Context, req interface{}) (string, error) {
+	var key string
+	if r, ok := req.(interface{ GetIdempotencyKey() string }); ok {
+		key = r.GetIdempotencyKey()
+	}
+	if md := metautils.ExtractIncoming(ctx).Get(IdempotencyKeyHeader); md != "" {
+		if key != "" && key != md {
+			return "", status.Error(codes.InvalidArgument, "idempotency key differs between the request and the metadata")
+		}
+		key = md
+	}
+	if len(key) > maxIdempotencyKeyLen {
+		return "", status.Errorf(codes.InvalidArgument, "idempotency key is longer than %d bytes", maxIdempotencyKeyLen)
+	}
+
+	return key, nil
+}
+
+// requestHash hashes the method and req without its idempotency key, which
+// may move between the request and the metadata across retries.
+func requestHash(method string, req proto.Message) ([]byte, error) {
+	req = proto.Clone(req)
+	m := proto.MessageReflect(req)
+	m.Clear(m.Descriptor().Fields().ByName("idempotency_key"))
+
+	b := proto.NewBuffer(nil)
+	b.SetDeterministic(true)
+	if err := b.Marshal(req); err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write(b.Bytes())
+	return h.Sum(nil), nil
+}
@@ -11,3 +11,8 @@ import (
 func LogRPCCalls(l *zap.Logger) grpc.UnaryServerInterceptor {
 	return grpc_zap.UnaryServerInterceptor(l)
 }
+
+// LogStreamRPCCalls logs all streaming RPC methods call.
+func LogStreamRPCCalls(l *zap.Logger) grpc.StreamServerInterceptor {
+	return grpc_zap.StreamServerInterceptor(l)
+}
@@ -0,0 +1,282 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/auth"
+	"github.com/golang/protobuf/ptypes"
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultLimitKey is the key of the limit applied to methods
+// without a limit of their own.
+const DefaultLimitKey = "*"
+
+// idleBucketTTL is how long the bucket of a caller that stopped
+// calling is kept around.
+const idleBucketTTL = 10 * time.Minute
+
+// Limit is a token bucket refilled with Rate tokens per second
+// that holds up to Burst tokens.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// ParseLimits parses a comma separated list of method=rate:burst pairs,
+// e.g. "*=10:20,/todo.v1.TodoService/Create=1:5".
+func ParseLimits(s string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("rate limit %q is not method=rate:burst", pair)
+		}
+		rb := strings.SplitN(kv[1], ":", 2)
+		if len(rb) != 2 {
+			return nil, fmt.Errorf("rate limit %q is not method=rate:burst", pair)
+		}
+		r, err := strconv.ParseFloat(rb[0], 64)
+		if err != nil || r <= 0 {
+			return nil, fmt.Errorf("rate limit %q has an invalid rate", pair)
+		}
+		b, err := strconv.Atoi(rb[1])
+		if err != nil || b <= 0 {
+			return nil, fmt.Errorf("rate limit %q has an invalid burst", pair)
+		}
+		limits[kv[0]] = Limit{Rate: r, Burst: b}
+	}
+
+	return limits, nil
+}
+
+// RateLimiter keeps a token bucket per caller and method.
+type RateLimiter struct {
+	mu        sync.Mutex
+	limits    map[string]Limit
+	buckets   map[bucketKey]*bucket
+	lastSweep time.Time
+}
+
+type bucketKey struct {
+	caller string
+	method string
+}
+
+type bucket struct {
+	lim      *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewRateLimiter creates a RateLimiter with limits keyed by full method
+// name. Methods without a limit use the DefaultLimitKey limit, if any,
+// except for the health and reflection services.
+func NewRateLimiter(limits map[string]Limit) *RateLimiter {
+	return &RateLimiter{
+		limits:    limits,
+		buckets:   make(map[bucketKey]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// SetLimits replaces the limits, resetting every caller's bucket.
+func (rl *RateLimiter) SetLimits(limits map[string]Limit) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.limits = limits
+	rl.buckets = make(map[bucketKey]*bucket)
+}
+
+// limiter returns the caller's bucket for method, nil if method has no
+// limit. The default limit doesn't apply to the public services, the same
+// that need no authentication, so that health checks of load balancers
+// aren't turned away.
+func (rl *RateLimiter) limiter(caller, method string, now time.Time) *rate.Limiter {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	limit, ok := rl.limits[method]
+	if !ok && !isPublic(method) {
+		limit, ok = rl.limits[DefaultLimitKey]
+	}
+	if !ok {
+		return nil
+	}
+
+	rl.sweep(now)
+
+	key := bucketKey{caller, method}
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{lim: rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)}
+		rl.buckets[key] = b
+	}
+	b.lastSeen = now
+
+	return b.lim
+}
+
+// reserve takes a token from the caller's bucket for method. If the
+// bucket is empty it returns how long the caller should wait instead.
+func (rl *RateLimiter) reserve(caller, method string) (time.Duration, bool) {
+	now := time.Now()
+	lim := rl.limiter(caller, method, now)
+	if lim == nil {
+		return 0, true
+	}
+
+	r := lim.ReserveN(now, 1)
+	if d := r.DelayFrom(now); d > 0 {
+		r.CancelAt(now)
+		return d, false
+	}
+
+	return 0, true
+}
+
+// wait takes a token from the caller's bucket for method, waiting for
+// one if the bucket is empty.
+func (rl *RateLimiter) wait(ctx context.Context, caller, method string) error {
+	lim := rl.limiter(caller, method, time.Now())
+	if lim == nil {
+		return nil
+	}
+	return lim.Wait(ctx)
+}
+
+// sweep drops the buckets of callers that have gone quiet.
+func (rl *RateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < time.Minute {
+		return
+	}
+	rl.lastSweep = now
+
+	for k, b := range rl.buckets {
+		if now.Sub(b.lastSeen) > idleBucketTTL {
+			delete(rl.buckets, k)
+		}
+	}
+}
+
+// RateLimitUnary rejects unary calls from callers that exceeded the
+// limit of the method with ResourceExhausted. Callers are identified by
+// principal, so it must run after AuthenticateUnary if auth is enabled.
+func RateLimitUnary(rl *RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := rateLimit(ctx, rl, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStream rejects streams from callers that exceeded the
+// limit of the method with ResourceExhausted. Each stream takes one token.
+//
+// Streams of the methods in perMessage also take a token of the method
+// they map to for each message they receive, waiting for it if the bucket
+// is empty, so that a stream standing in for many calls of a unary
+// method, such as an import for creates, is held to the same limit.
+// Streams whose first message is a dry run, such as an import with
+// dry_run, change nothing and take no tokens for their messages.
+func RateLimitStream(rl *RateLimiter, perMessage map[string]string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := rateLimit(ss.Context(), rl, info.FullMethod); err != nil {
+			return err
+		}
+		if method, ok := perMessage[info.FullMethod]; ok {
+			ss = &limitedStream{ServerStream: ss, rl: rl, caller: caller(ss.Context()), method: method}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// limitedStream takes a token of method for each message received.
+type limitedStream struct {
+	grpc.ServerStream
+	rl     *RateLimiter
+	caller string
+	method string
+	// received and dryRun are set by the first message, which decides
+	// whether the stream is a dry run, as it does for its handler.
+	received bool
+	dryRun   bool
+}
+
+// dryRunner is a message that may be a dry run.
+type dryRunner interface {
+	GetDryRun() bool
+}
+
+func (s *limitedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if !s.received {
+		s.received = true
+		d, ok := m.(dryRunner)
+		s.dryRun = ok && d.GetDryRun()
+	}
+	if s.dryRun {
+		return nil
+	}
+
+	ctx := s.Context()
+	if err := s.rl.wait(ctx, s.caller, s.method); err != nil {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %s: %v", s.method, err)
+	}
+	return nil
+}
+
+func rateLimit(ctx context.Context, rl *RateLimiter, fullMethod string) error {
+	delay, ok := rl.reserve(caller(ctx), fullMethod)
+	if ok {
+		return nil
+	}
+
+	st := status.Newf(codes.ResourceExhausted, "rate limit exceeded for %s, retry in %v", fullMethod, delay)
+	st, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(delay)})
+	if err != nil {
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %s", fullMethod)
+	}
+
+	return st.Err()
+}
+
+// caller identifies the caller by its principal, falling back to the
+// peer's IP address for anonymous callers.
+func caller(ctx context.Context) string {
+	if p := auth.FromContext(ctx); p.Subject != "" {
+		return "principal:" + p.Subject
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "unknown"
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return "peer:" + p.Addr.String()
+	}
+	return "peer:" + host
+}
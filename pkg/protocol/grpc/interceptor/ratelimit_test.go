@@ -0,0 +1,109 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/dikaeinstein/prototodo/pkg/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimiterDefaultLimit(t *testing.T) {
+	rl := NewRateLimiter(map[string]Limit{
+		DefaultLimitKey:                {Rate: 1, Burst: 1},
+		"/grpc.health.v1.Health/Watch": {Rate: 1, Burst: 1},
+	})
+
+	tests := []struct {
+		method  string
+		limited bool
+	}{
+		{"/todo.v1.TodoService/ReadAll", true},
+		{"/grpc.health.v1.Health/Check", false},
+		{"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo", false},
+		// A limit of its own still applies.
+		{"/grpc.health.v1.Health/Watch", true},
+	}
+
+	for _, tt := range tests {
+		// The burst lets the first call through.
+		for i := 0; i < 2; i++ {
+			_, ok := rl.reserve("peer:10.0.0.1", tt.method)
+			if i == 1 && ok == tt.limited {
+				t.Errorf("second call of %s allowed = %v, want %v", tt.method, ok, !tt.limited)
+			}
+		}
+	}
+}
+
+// importStream receives reqs in turn.
+type importStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	reqs []*pb.ImportRequest
+}
+
+func (s *importStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *importStream) RecvMsg(m interface{}) error {
+	*m.(*pb.ImportRequest) = *s.reqs[0]
+	s.reqs = s.reqs[1:]
+	return nil
+}
+
+func TestRateLimitStreamDryRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		dryRuns []bool
+		// limited is the index of the first message that runs out of
+		// tokens, -1 if none does.
+		limited int
+	}{
+		{"import", []bool{false, false}, 1},
+		{"dry run", []bool{true, true, true}, -1},
+		// The first message decides, like it does for the handler.
+		{"later messages claiming a dry run", []bool{false, true}, 1},
+		{"later messages claiming no dry run", []bool{true, false, false}, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := NewRateLimiter(map[string]Limit{"/todo.v1.TodoService/Create": {Rate: 0.001, Burst: 1}})
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			in := &importStream{ctx: ctx}
+			for _, dryRun := range tt.dryRuns {
+				in.reqs = append(in.reqs, &pb.ImportRequest{DryRun: dryRun})
+			}
+			info := &grpc.StreamServerInfo{FullMethod: "/todo.v1.TodoService/Import"}
+			interceptor := RateLimitStream(rl, map[string]string{info.FullMethod: "/todo.v1.TodoService/Create"})
+
+			err := interceptor(nil, in, info, func(srv interface{}, ss grpc.ServerStream) error {
+				for i := range tt.dryRuns {
+					err := ss.RecvMsg(&pb.ImportRequest{})
+					if code := status.Code(err); code == codes.ResourceExhausted {
+						if i != tt.limited {
+							t.Errorf("message %d ran out of tokens, want %d", i, tt.limited)
+						}
+						return nil
+					} else if err != nil {
+						return err
+					}
+				}
+				if tt.limited != -1 {
+					t.Errorf("no message ran out of tokens, want %d", tt.limited)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
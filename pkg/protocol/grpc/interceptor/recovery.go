@@ -0,0 +1,47 @@
+package interceptor
+
+import (
+	"context"
+
+	"github.com/dikaeinstein/prototodo/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RecoverUnary turns panics in unary handlers into codes.Internal errors
+// and logs them with their stack trace, instead of crashing the server.
+func RecoverUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (_ interface{}, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(ctx, p)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// RecoverStream turns panics in stream handlers into codes.Internal errors
+// and logs them with their stack trace, instead of crashing the server.
+func RecoverStream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(ss.Context(), p)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// recovered logs the panic through the request-scoped logger, which
+// already carries the method and request ID.
+func recovered(ctx context.Context, p interface{}) error {
+	logger.FromContext(ctx).Error("recovered from panic",
+		zap.Any("panic", p),
+		zap.Stack("stacktrace"),
+	)
+	return status.Error(codes.Internal, "internal server error")
+}
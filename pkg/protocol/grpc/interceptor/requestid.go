@@ -0,0 +1,55 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/dikaeinstein/prototodo/pkg/logger"
+	"github.com/grpc-ecosystem/go-grpc-middleware/util/metautils"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader is the metadata key that carries the request ID.
+const RequestIDHeader = "x-request-id"
+
+// maxRequestIDLen stops callers from bloating every log line.
+const maxRequestIDLen = 128
+
+// RequestIDUnary tags the request-scoped logger with the request ID taken
+// from the incoming metadata, or a generated one, and echoes it back in the
+// response header. It must run after LogRPCCalls.
+func RequestIDUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		id := requestID(ctx)
+		logger.AddFields(ctx, zap.String("request_id", id))
+		grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id))
+		return handler(ctx, req)
+	}
+}
+
+// RequestIDStream tags the request-scoped logger with the request ID taken
+// from the incoming metadata, or a generated one, and echoes it back in the
+// response header. It must run after LogRPCCalls.
+func RequestIDStream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		id := requestID(ss.Context())
+		logger.AddFields(ss.Context(), zap.String("request_id", id))
+		ss.SetHeader(metadata.Pairs(RequestIDHeader, id))
+		return handler(srv, ss)
+	}
+}
+
+func requestID(ctx context.Context) string {
+	if id := metautils.ExtractIncoming(ctx).Get(RequestIDHeader); id != "" && len(id) <= maxRequestIDLen {
+		return id
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
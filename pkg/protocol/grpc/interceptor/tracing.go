@@ -0,0 +1,53 @@
+package interceptor
+
+import (
+	"context"
+
+	"github.com/dikaeinstein/prototodo/pkg/logger"
+	"github.com/dikaeinstein/prototodo/pkg/tracing"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// TraceUnary starts a server span for every unary call, continuing the
+// W3C trace context found in the incoming metadata, and tags the
+// request-scoped logger with the trace ID. It must run after LogRPCCalls.
+func TraceUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := startServerSpan(ctx, info.FullMethod)
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		tracing.EndRPC(span, err)
+		return resp, err
+	}
+}
+
+// TraceStream starts a server span for every streaming call, continuing
+// the W3C trace context found in the incoming metadata, and tags the
+// request-scoped logger with the trace ID. It must run after LogStreamRPCCalls.
+func TraceStream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startServerSpan(ss.Context(), info.FullMethod)
+		defer span.End()
+
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctx
+		err := handler(srv, wrapped)
+		tracing.EndRPC(span, err)
+		return err
+	}
+}
+
+func startServerSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	ctx, span := tracing.Tracer().Start(tracing.Extract(ctx), fullMethod,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(semconv.RPCSystemGRPC),
+	)
+	logger.AddFields(ctx, zap.String("trace_id", span.SpanContext().TraceID().String()))
+
+	return ctx, span
+}
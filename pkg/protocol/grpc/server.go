@@ -2,7 +2,11 @@ package grpc
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
 
 	pb "github.com/dikaeinstein/prototodo/pkg/proto"
 	"github.com/dikaeinstein/prototodo/pkg/todo"
@@ -15,6 +19,12 @@ import (
 
 var (
 	errClientCancelled = status.Error(codes.Canceled, "Client cancelled, abandoning.")
+	errMissingTodo     = status.Error(codes.InvalidArgument, "Request field todo is required")
+)
+
+const (
+	defaultSearchPageSize = 20
+	maxSearchPageSize     = 100
 )
 
 // Service provides an interface to operate on Todo items.
@@ -24,6 +34,7 @@ type Service interface {
 	Read(ctx context.Context, id uint) (todo.Todo, error)
 	ReadAll(ctx context.Context) (chan todo.Todo, error)
 	Update(ctx context.Context, todoID uint, t todo.Todo) (todo.Todo, error)
+	Search(ctx context.Context, query string, limit, offset int) ([]todo.SearchResult, error)
 }
 
 type todoHandler struct {
@@ -37,6 +48,10 @@ func NewGRPCTodoHandler(s Service) pb.TodoServiceServer {
 }
 
 func (h *todoHandler) Create(ctx context.Context, req *pb.CreateRequest) (*pb.CreateResponse, error) {
+	if req.Todo == nil {
+		return nil, errMissingTodo
+	}
+
 	t, err := makeTodo(req.Todo)
 	if err != nil {
 		return nil, err
@@ -131,6 +146,10 @@ func (h *todoHandler) ReadAll(ctx context.Context, req *pb.ReadAllRequest) (*pb.
 }
 
 func (h *todoHandler) Update(ctx context.Context, req *pb.UpdateRequest) (*pb.UpdateResponse, error) {
+	if req.Todo == nil {
+		return nil, errMissingTodo
+	}
+
 	t, err := makeTodo(req.Todo)
 	if err != nil {
 		return nil, err
@@ -154,6 +173,180 @@ func (h *todoHandler) Update(ctx context.Context, req *pb.UpdateRequest) (*pb.Up
 	return &pb.UpdateResponse{Updated: tProto}, nil
 }
 
+func (h *todoHandler) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, status.Error(codes.InvalidArgument, "Request field query is required")
+	}
+
+	pageSize := int(req.PageSize)
+	if pageSize <= 0 {
+		pageSize = defaultSearchPageSize
+	}
+	if pageSize > maxSearchPageSize {
+		pageSize = maxSearchPageSize
+	}
+
+	offset, err := decodePageToken(req.PageToken)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"Request field page_token is invalid: %v", err)
+	}
+
+	// Check that there's still a client waiting for the response.
+	if ctx.Err() == context.Canceled {
+		return nil, errClientCancelled
+	}
+
+	// Fetch one extra result to find out if there's a next page.
+	results, err := h.service.Search(ctx, req.Query, pageSize+1, offset)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal,
+			"Failed to search todo items: %v", err)
+	}
+
+	var nextPageToken string
+	if len(results) > pageSize {
+		results = results[:pageSize]
+		nextPageToken = encodePageToken(offset + pageSize)
+	}
+
+	rrProto := make([]*pb.SearchResult, 0, len(results))
+	for _, r := range results {
+		tProto, err := makeTodoProto(r.Todo)
+		if err != nil {
+			return nil, err
+		}
+		rrProto = append(rrProto, &pb.SearchResult{
+			Todo:               tProto,
+			Rank:               float32(r.Rank),
+			TitleSnippet:       r.TitleSnippet,
+			DescriptionSnippet: r.DescriptionSnippet,
+		})
+	}
+
+	return &pb.SearchResponse{Results: rrProto, NextPageToken: nextPageToken}, nil
+}
+
+func (h *todoHandler) Import(stream pb.TodoService_ImportServer) error {
+	ctx := stream.Context()
+	resp := &pb.ImportResponse{Errors: make([]*pb.ImportError, 0)}
+
+	for n := int32(1); ; n++ {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+		if n == 1 {
+			resp.DryRun = req.DryRun
+		}
+
+		line := req.Line
+		if line <= 0 {
+			line = n
+		}
+		if err := h.importTodo(ctx, req.Todo, resp.DryRun); err != nil {
+			resp.Errors = append(resp.Errors, &pb.ImportError{
+				Line:    line,
+				Message: status.Convert(err).Message(),
+			})
+			continue
+		}
+		resp.Imported++
+	}
+}
+
+// importTodo creates tProto, keeping its creation time, unless dryRun.
+func (h *todoHandler) importTodo(ctx context.Context, tProto *pb.Todo, dryRun bool) error {
+	if tProto == nil {
+		return errMissingTodo
+	}
+
+	t, err := makeTodo(tProto)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(t.Title) == "" {
+		return status.Error(codes.InvalidArgument, "Request field todo.title is required")
+	}
+	if c := tProto.GetCreatedAt(); c != nil {
+		createdAt, err := ptypes.Timestamp(c)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument,
+				"Request field todo.created_at is invalid: %v", err)
+		}
+		t.CreatedAt = createdAt
+	}
+
+	// Check that there's still a client waiting for the response.
+	if ctx.Err() == context.Canceled {
+		return errClientCancelled
+	}
+	if dryRun {
+		return nil
+	}
+
+	if _, err := h.service.Create(ctx, *t); err != nil {
+		return status.Errorf(codes.Internal,
+			"failed to create todo: %v", err)
+	}
+	return nil
+}
+
+func (h *todoHandler) Export(req *pb.ExportRequest, stream pb.TodoService_ExportServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	ch, err := h.service.ReadAll(ctx)
+	if err != nil {
+		return status.Errorf(codes.Internal,
+			"Failed to fetch todo items: %v", err)
+	}
+
+	for t := range ch {
+		tProto, err := makeTodoProto(t)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&pb.ExportResponse{Todo: tProto}); err != nil {
+			return err
+		}
+	}
+
+	// Check that the todos weren't cut short by the client going away.
+	if ctx.Err() == context.Canceled {
+		return errClientCancelled
+	}
+	return nil
+}
+
+// encodePageToken makes an opaque page token out of a result offset.
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.Atoi(string(b))
+	if err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative offset %d", offset)
+	}
+
+	return offset, nil
+}
+
 func makeParseTimeStampErrorMsg(field string, err error) string {
 	return fmt.Sprintf("failed to convert %s to a google.protobuf.Timestamp proto."+
 		"Resulting Timestamp is invalid: %v", field, err)
@@ -188,8 +381,10 @@ func makeTodoProto(t todo.Todo) (*pb.Todo, error) {
 
 	return &pb.Todo{
 		Id:          int64(t.ID),
+		OwnerId:     t.OwnerID,
 		Description: t.Description,
 		Title:       t.Title,
+		Priority:    t.Priority,
 		Reminder:    reminderProto,
 		CreatedAt:   createdAtProto,
 		UpdatedAt:   updatedAtProto,
@@ -212,5 +407,11 @@ func makeTodo(tProto *pb.Todo) (*todo.Todo, error) {
 	t.Description = tProto.GetDescription()
 	t.Title = tProto.GetTitle()
 
+	if !todo.ValidPriority(tProto.GetPriority()) {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"Request field todo.priority must be a letter from A to Z, got %q", tProto.GetPriority())
+	}
+	t.Priority = tProto.GetPriority()
+
 	return &t, nil
 }
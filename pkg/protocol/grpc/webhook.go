@@ -0,0 +1,261 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	pb "github.com/dikaeinstein/prototodo/pkg/proto"
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+	"github.com/dikaeinstein/prototodo/pkg/todo/storage"
+	"github.com/dikaeinstein/prototodo/pkg/webhook"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultDeliveriesPageSize = 20
+	maxDeliveriesPageSize     = 100
+)
+
+// WebhookService provides an interface to manage the caller's webhooks.
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, w todo.Webhook) (todo.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]todo.Webhook, error)
+	DeleteWebhook(ctx context.Context, id uint) error
+	ListDeliveries(ctx context.Context, webhookID uint, status string, limit, offset int) ([]todo.WebhookDelivery, error)
+}
+
+type webhookHandler struct {
+	service WebhookService
+}
+
+// NewGRPCWebhookHandler creates a new webhookHandler
+// which implements the pb.WebhookServiceServer interface
+func NewGRPCWebhookHandler(s WebhookService) pb.WebhookServiceServer {
+	return &webhookHandler{s}
+}
+
+func (h *webhookHandler) CreateWebhook(ctx context.Context, req *pb.CreateWebhookRequest) (*pb.CreateWebhookResponse, error) {
+	if req.Webhook == nil {
+		return nil, status.Error(codes.InvalidArgument, "Request field webhook is required")
+	}
+
+	w, err := makeWebhook(req.Webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check that there's still a client waiting for the response.
+	if ctx.Err() == context.Canceled {
+		return nil, errClientCancelled
+	}
+
+	created, err := h.service.CreateWebhook(ctx, w)
+	if err != nil {
+		if errors.Is(err, webhook.ErrForbiddenURL) {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"Request field webhook.url must be public: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal,
+			"Failed to create webhook: %v", err)
+	}
+
+	wProto, err := makeWebhookProto(created)
+	if err != nil {
+		return nil, err
+	}
+	// The secret is only ever returned here.
+	wProto.Secret = created.Secret
+
+	return &pb.CreateWebhookResponse{Webhook: wProto}, nil
+}
+
+func (h *webhookHandler) ListWebhooks(ctx context.Context, req *pb.ListWebhooksRequest) (*pb.ListWebhooksResponse, error) {
+	// Check that there's still a client waiting for the response.
+	if ctx.Err() == context.Canceled {
+		return nil, errClientCancelled
+	}
+
+	ww, err := h.service.ListWebhooks(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal,
+			"Failed to fetch webhooks: %v", err)
+	}
+
+	wwProto := make([]*pb.Webhook, 0, len(ww))
+	for _, w := range ww {
+		wProto, err := makeWebhookProto(w)
+		if err != nil {
+			return nil, err
+		}
+		wwProto = append(wwProto, wProto)
+	}
+
+	return &pb.ListWebhooksResponse{Webhooks: wwProto}, nil
+}
+
+func (h *webhookHandler) DeleteWebhook(ctx context.Context, req *pb.DeleteWebhookRequest) (*pb.DeleteWebhookResponse, error) {
+	// Check that there's still a client waiting for the response.
+	if ctx.Err() == context.Canceled {
+		return nil, errClientCancelled
+	}
+
+	if err := h.service.DeleteWebhook(ctx, uint(req.Id)); err != nil {
+		if err == storage.ErrWebhookNotFound {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
+		return nil, status.Errorf(codes.Internal,
+			"Failed to delete webhook: %v", err)
+	}
+
+	return &pb.DeleteWebhookResponse{Deleted: req.Id}, nil
+}
+
+func (h *webhookHandler) ListDeliveries(ctx context.Context, req *pb.ListDeliveriesRequest) (*pb.ListDeliveriesResponse, error) {
+	switch req.Status {
+	case "", todo.DeliveryPending, todo.DeliverySucceeded, todo.DeliveryDead:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument,
+			"Request field status must be %s, %s or %s, got %q",
+			todo.DeliveryPending, todo.DeliverySucceeded, todo.DeliveryDead, req.Status)
+	}
+
+	pageSize := int(req.PageSize)
+	if pageSize <= 0 {
+		pageSize = defaultDeliveriesPageSize
+	}
+	if pageSize > maxDeliveriesPageSize {
+		pageSize = maxDeliveriesPageSize
+	}
+
+	offset, err := decodePageToken(req.PageToken)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"Request field page_token is invalid: %v", err)
+	}
+
+	// Check that there's still a client waiting for the response.
+	if ctx.Err() == context.Canceled {
+		return nil, errClientCancelled
+	}
+
+	// Fetch one extra delivery to find out if there's a next page.
+	dd, err := h.service.ListDeliveries(ctx, uint(req.WebhookId), req.Status, pageSize+1, offset)
+	if err != nil {
+		if err == storage.ErrWebhookNotFound {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
+		return nil, status.Errorf(codes.Internal,
+			"Failed to fetch deliveries: %v", err)
+	}
+
+	var nextPageToken string
+	if len(dd) > pageSize {
+		dd = dd[:pageSize]
+		nextPageToken = encodePageToken(offset + pageSize)
+	}
+
+	ddProto := make([]*pb.Delivery, 0, len(dd))
+	for _, d := range dd {
+		dProto, err := makeDeliveryProto(d)
+		if err != nil {
+			return nil, err
+		}
+		ddProto = append(ddProto, dProto)
+	}
+
+	return &pb.ListDeliveriesResponse{Deliveries: ddProto, NextPageToken: nextPageToken}, nil
+}
+
+func makeWebhook(wProto *pb.Webhook) (todo.Webhook, error) {
+	u, err := url.Parse(wProto.GetUrl())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return todo.Webhook{}, status.Errorf(codes.InvalidArgument,
+			"Request field webhook.url must be an http or https URL, got %q", wProto.GetUrl())
+	}
+
+	var events []string
+	seen := make(map[string]bool)
+	for _, e := range wProto.GetEvents() {
+		if !isEvent(e) {
+			return todo.Webhook{}, status.Errorf(codes.InvalidArgument,
+				"Request field webhook.events must be among %s, got %q", strings.Join(todo.Events, ", "), e)
+		}
+		if !seen[e] {
+			seen[e] = true
+			events = append(events, e)
+		}
+	}
+
+	return todo.Webhook{
+		URL:    u.String(),
+		Events: strings.Join(events, ","),
+		Secret: wProto.GetSecret(),
+	}, nil
+}
+
+func isEvent(e string) bool {
+	for _, event := range todo.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// makeWebhookProto converts w without its secret.
+func makeWebhookProto(w todo.Webhook) (*pb.Webhook, error) {
+	createdAtProto, err := ptypes.TimestampProto(w.CreatedAt)
+	if err != nil {
+		return nil, status.Error(codes.Internal,
+			makeParseTimeStampErrorMsg("CreatedAt", err))
+	}
+
+	var events []string
+	if w.Events != "" {
+		events = strings.Split(w.Events, ",")
+	}
+
+	return &pb.Webhook{
+		Id:        int64(w.ID),
+		Url:       w.URL,
+		Events:    events,
+		OwnerId:   w.OwnerID,
+		CreatedAt: createdAtProto,
+	}, nil
+}
+
+func makeDeliveryProto(d todo.WebhookDelivery) (*pb.Delivery, error) {
+	nextAttemptAtProto, err := ptypes.TimestampProto(d.NextAttemptAt)
+	if err != nil {
+		return nil, status.Error(codes.Internal,
+			makeParseTimeStampErrorMsg("NextAttemptAt", err))
+	}
+	createdAtProto, err := ptypes.TimestampProto(d.CreatedAt)
+	if err != nil {
+		return nil, status.Error(codes.Internal,
+			makeParseTimeStampErrorMsg("CreatedAt", err))
+	}
+	updatedAtProto, err := ptypes.TimestampProto(d.UpdatedAt)
+	if err != nil {
+		return nil, status.Error(codes.Internal,
+			makeParseTimeStampErrorMsg("UpdatedAt", err))
+	}
+
+	return &pb.Delivery{
+		Id:            int64(d.ID),
+		WebhookId:     int64(d.WebhookID),
+		EventId:       d.EventID,
+		Event:         d.Event,
+		Status:        d.Status,
+		Attempts:      int32(d.Attempts),
+		ResponseCode:  int32(d.ResponseCode),
+		LastError:     d.LastError,
+		NextAttemptAt: nextAttemptAtProto,
+		CreatedAt:     createdAtProto,
+		UpdatedAt:     updatedAtProto,
+	}, nil
+}
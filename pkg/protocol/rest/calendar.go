@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/client"
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// CalendarPath is the path of the iCalendar feed of the caller's todos.
+const CalendarPath = "/v1/todos.ics"
+
+// calendarFeed serves the caller's todos as an iCalendar feed that calendar
+// apps can subscribe to. They rarely send bearer tokens, so the token may
+// also be the password of basic auth or the token query parameter. Either
+// is only accepted over TLS, which the feed therefore needs whenever
+// authentication is on: a token sent in the clear would be as good as
+// published, all the more so in a URL, which ends up in logs.
+//
+// The feed has an ETag, its hash, and a Last-Modified, the last update of
+// its todos, so apps polling it get 304 Not Modified until it changes. A
+// deleted todo changes the ETag but not Last-Modified, which is why the
+// ETag takes precedence.
+type calendarFeed struct {
+	mux    *runtime.ServeMux
+	client *client.Client
+}
+
+func (f *calendarFeed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	token := feedToken(r)
+	if token != "" && r.TLS == nil {
+		http.Error(w, "tokens are only accepted over TLS", http.StatusBadRequest)
+		return
+	}
+
+	// The token is passed on like the gateway passes on the Authorization
+	// header, with the client address for rate limiting.
+	ctx, err := runtime.AnnotateContext(r.Context(), f.mux, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if token != "" {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		md = md.Copy()
+		md.Set("authorization", "Bearer "+token)
+		ctx = metadata.NewOutgoingContext(ctx, md)
+	}
+
+	tt, err := f.client.Export(ctx).All()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var modified time.Time
+	for _, t := range tt {
+		if t.UpdatedAt.After(modified) {
+			modified = t.UpdatedAt
+		}
+	}
+	var buf bytes.Buffer
+	if err := todo.WriteCalendar(&buf, "Todos", tt); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sum := sha256.Sum256(buf.Bytes())
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	// Caches must check with the server, which answers 304 if the feed is
+	// unchanged, and must not share feeds between users.
+	w.Header().Set("Cache-Control", "private, no-cache")
+	http.ServeContent(w, r, "todos.ics", modified, bytes.NewReader(buf.Bytes()))
+}
+
+// feedToken returns the token in the token query parameter or the password
+// of basic auth, if any.
+func feedToken(r *http.Request) string {
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token
+	}
+	if _, password, ok := r.BasicAuth(); ok {
+		return password
+	}
+	return ""
+}
+
+// writeError answers with the HTTP status of the gRPC error, asking
+// calendar apps for credentials if there were none or they were invalid.
+func writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	if st.Code() == codes.Unauthenticated {
+		w.Header().Set("WWW-Authenticate", `Basic realm="todos"`)
+	}
+	http.Error(w, st.Message(), runtime.HTTPStatusFromCode(st.Code()))
+}
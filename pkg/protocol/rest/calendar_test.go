@@ -0,0 +1,144 @@
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/client"
+	pb "github.com/dikaeinstein/prototodo/pkg/proto"
+	g "github.com/dikaeinstein/prototodo/pkg/protocol/grpc"
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+	"github.com/dikaeinstein/prototodo/pkg/todo/service"
+	"github.com/dikaeinstein/prototodo/pkg/todo/storage"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type nopPublisher struct{}
+
+func (nopPublisher) Publish(context.Context, todo.Event) {}
+
+// newFeed serves the todo service from a memory store in process and
+// returns a feed of it and a client to change the todos with.
+func newFeed(t *testing.T) (*calendarFeed, *client.Client) {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	pb.RegisterTodoServiceServer(s, g.NewGRPCTodoHandler(service.New(storage.NewMemoryStore(), nopPublisher{})))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	c, err := client.New(client.WithConn(conn), client.WithTimeout(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &calendarFeed{mux: runtime.NewServeMux(), client: c}, c
+}
+
+func TestCalendarFeedConditionalGet(t *testing.T) {
+	feed, c := newFeed(t)
+	if _, err := c.Create(context.Background(), todo.Todo{Title: "milk"}); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, CalendarPath, nil)
+		for k, vv := range header {
+			r.Header[k] = vv
+		}
+		w := httptest.NewRecorder()
+		feed.ServeHTTP(w, r)
+		return w
+	}
+
+	first := get(nil)
+	if first.Code != http.StatusOK {
+		t.Fatalf("GET = %d, want %d", first.Code, http.StatusOK)
+	}
+	etag, modified := first.Header().Get("ETag"), first.Header().Get("Last-Modified")
+	if etag == "" || modified == "" {
+		t.Fatalf("ETag = %q, Last-Modified = %q, want both set", etag, modified)
+	}
+	if ct := first.Header().Get("Content-Type"); ct != "text/calendar; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   int
+	}{
+		{"matching ETag", http.Header{"If-None-Match": {etag}}, http.StatusNotModified},
+		{"other ETag", http.Header{"If-None-Match": {`"other"`}}, http.StatusOK},
+		{"not modified since", http.Header{"If-Modified-Since": {modified}}, http.StatusNotModified},
+		{"ETag takes precedence", http.Header{"If-None-Match": {`"other"`}, "If-Modified-Since": {modified}}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := get(tt.header).Code; got != tt.want {
+			t.Errorf("%s: GET = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	// A new todo changes the ETag, so the old one no longer matches.
+	time.Sleep(time.Millisecond)
+	if _, err := c.Create(context.Background(), todo.Todo{Title: "eggs"}); err != nil {
+		t.Fatal(err)
+	}
+	changed := get(http.Header{"If-None-Match": {etag}})
+	if changed.Code != http.StatusOK {
+		t.Fatalf("GET after a change = %d, want %d", changed.Code, http.StatusOK)
+	}
+	if changed.Header().Get("ETag") == etag {
+		t.Errorf("ETag didn't change with the todos")
+	}
+}
+
+func TestCalendarFeedTokensNeedTLS(t *testing.T) {
+	feed, _ := newFeed(t)
+
+	tests := []struct {
+		name  string
+		path  string
+		basic bool
+		tls   bool
+		want  int
+	}{
+		{"no token", CalendarPath, false, false, http.StatusOK},
+		{"query token", CalendarPath + "?token=secret", false, false, http.StatusBadRequest},
+		{"basic auth", CalendarPath, true, false, http.StatusBadRequest},
+		{"query token over TLS", CalendarPath + "?token=secret", false, true, http.StatusOK},
+		{"basic auth over TLS", CalendarPath, true, true, http.StatusOK},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.basic {
+			r.SetBasicAuth("me", "secret")
+		}
+		if tt.tls {
+			r.TLS = &tls.ConnectionState{}
+		}
+		w := httptest.NewRecorder()
+		feed.ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: GET = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/dikaeinstein/prototodo/pkg/client"
+	pb "github.com/dikaeinstein/prototodo/pkg/proto"
+	"github.com/dikaeinstein/prototodo/pkg/protocol/grpc/interceptor"
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc"
+)
+
+// NewGateway returns an http.Handler that serves the REST routes declared
+// by the google.api.http annotations in todo.proto, calling the TodoService
+// over conn. gRPC errors are mapped to the matching HTTP status and the
+// Authorization and Idempotency-Key headers are passed on as metadata.
+// It also serves the iCalendar feed of the caller's todos on CalendarPath.
+func NewGateway(ctx context.Context, conn *grpc.ClientConn) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{OrigName: true, EmitDefaults: true}),
+		runtime.WithForwardResponseOption(setStatus),
+		runtime.WithIncomingHeaderMatcher(matchHeader),
+	)
+	if err := pb.RegisterTodoServiceHandler(ctx, mux, conn); err != nil {
+		return nil, err
+	}
+
+	c, err := client.New(client.WithConn(conn), client.WithTimeout(0))
+	if err != nil {
+		return nil, err
+	}
+	h := http.NewServeMux()
+	h.Handle(CalendarPath, &calendarFeed{mux: mux, client: c})
+	h.Handle("/", mux)
+
+	return h, nil
+}
+
+// setStatus answers a successful create with 201 Created instead of 200.
+func setStatus(ctx context.Context, w http.ResponseWriter, m proto.Message) error {
+	if _, ok := m.(*pb.CreateResponse); ok {
+		w.WriteHeader(http.StatusCreated)
+	}
+	return nil
+}
+
+// matchHeader passes the Idempotency-Key header on as metadata, besides the
+// headers passed on by default.
+func matchHeader(key string) (string, bool) {
+	if strings.EqualFold(key, "Idempotency-Key") {
+		return interceptor.IdempotencyKeyHeader, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
@@ -0,0 +1,40 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"google.golang.org/grpc"
+)
+
+// allowedHeaders are the request headers browsers may send cross-origin:
+// the ones the grpc-web client library sets plus our own metadata.
+var allowedHeaders = []string{
+	"content-type",
+	"x-grpc-web",
+	"x-user-agent",
+	"grpc-timeout",
+	"authorization",
+	"x-request-id",
+}
+
+// NewHandler returns an http.Handler that serves s to browsers over
+// grpc-web. Cross-origin calls are allowed from allowedOrigins only,
+// or from any origin if it contains "*".
+func NewHandler(s *grpc.Server, allowedOrigins []string) http.Handler {
+	return grpcweb.WrapServer(s,
+		grpcweb.WithOriginFunc(originFunc(allowedOrigins)),
+		grpcweb.WithAllowedRequestHeaders(allowedHeaders),
+	)
+}
+
+func originFunc(allowed []string) func(origin string) bool {
+	return func(origin string) bool {
+		for _, o := range allowed {
+			if o == "*" || o == origin {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,219 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Reloader serves a TLS certificate and CA bundle read from disk,
+// and reloads them when the files change so certificates can be
+// rotated without a restart.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	l        *zap.Logger
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	caPool  *x509.CertPool
+	modTime time.Time
+}
+
+// NewReloader loads the certificate pair in certFile and keyFile and,
+// if caFile isn't empty, the PEM encoded CA bundle in caFile.
+func NewReloader(certFile, keyFile, caFile string, l *zap.Logger) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, caFile: caFile, l: l}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload reads the certificate pair and CA bundle from disk. The current
+// ones are kept if any of the files can't be loaded.
+func (r *Reloader) Reload() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %v", err)
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		pem, err := ioutil.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA bundle: %v", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("no certificates found in CA bundle")
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.caPool = pool
+	r.modTime = modTime
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Run checks the files for changes every interval and reloads them
+// until stop is closed.
+func (r *Reloader) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		modTime, err := r.latestModTime()
+		if err != nil {
+			r.l.Error("failed to stat certificates", zap.Error(err))
+			continue
+		}
+
+		r.mu.RLock()
+		changed := modTime.After(r.modTime)
+		r.mu.RUnlock()
+		if !changed {
+			continue
+		}
+
+		if err := r.Reload(); err != nil {
+			r.l.Error("failed to reload certificates", zap.Error(err))
+			continue
+		}
+		r.l.Info("reloaded certificates", zap.String("cert_file", r.certFile))
+	}
+}
+
+// GetCertificate returns the current certificate. It is meant to be
+// used as tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// GetClientCertificate returns the current certificate. It is meant to be
+// used as tls.Config.GetClientCertificate.
+func (r *Reloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// CAPool returns the current CA bundle, nil if none was configured.
+func (r *Reloader) CAPool() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.caPool
+}
+
+// ErrNoClientCA is returned for server configs verifying client
+// certificates without a CA bundle, which would accept any certificate
+// issued by the system roots.
+var ErrNoClientCA = errors.New("client certificates can't be verified without a CA bundle")
+
+// ServerConfig returns a gRPC server tls.Config that verifies client
+// certificates against the CA bundle according to clientAuth. It returns
+// ErrNoClientCA if clientAuth verifies certificates and the Reloader has
+// no CA bundle.
+func (r *Reloader) ServerConfig(clientAuth tls.ClientAuthType) (*tls.Config, error) {
+	return r.serverConfig(clientAuth, []string{"h2"})
+}
+
+// HTTPServerConfig is like ServerConfig but also negotiates HTTP/1.1,
+// for HTTP servers such as the REST gateway and grpc-web.
+func (r *Reloader) HTTPServerConfig(clientAuth tls.ClientAuthType) (*tls.Config, error) {
+	return r.serverConfig(clientAuth, []string{"h2", "http/1.1"})
+}
+
+func (r *Reloader) serverConfig(clientAuth tls.ClientAuthType, nextProtos []string) (*tls.Config, error) {
+	verify := clientAuth >= tls.VerifyClientCertIfGiven
+	if verify && r.CAPool() == nil {
+		return nil, ErrNoClientCA
+	}
+
+	base := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+		ClientAuth:     clientAuth,
+		NextProtos:     nextProtos,
+	}
+
+	// Build the config per handshake so a reloaded CA bundle is used
+	// for verifying client certificates.
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c := base.Clone()
+			c.ClientCAs = r.CAPool()
+			if verify && c.ClientCAs == nil {
+				return nil, ErrNoClientCA
+			}
+			return c, nil
+		},
+	}, nil
+}
+
+// ClientConfig returns a client tls.Config that presents the current
+// certificate and trusts the CA bundle loaded when it is called.
+func (r *Reloader) ClientConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:           tls.VersionTLS12,
+		GetClientCertificate: r.GetClientCertificate,
+		RootCAs:              r.CAPool(),
+	}
+}
+
+// ParseClientAuth maps none, optional and required to the
+// matching tls.ClientAuthType.
+func ParseClientAuth(s string) (tls.ClientAuthType, error) {
+	switch s {
+	case "", "none":
+		return tls.NoClientCert, nil
+	case "optional":
+		return tls.VerifyClientCertIfGiven, nil
+	case "required":
+		return tls.RequireAndVerifyClientCert, nil
+	}
+	return tls.NoClientCert, fmt.Errorf("unknown client auth mode %q", s)
+}
+
+func (r *Reloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, f := range []string{r.certFile, r.keyFile, r.caFile} {
+		if f == "" {
+			continue
+		}
+		fi, err := os.Stat(f)
+		if err != nil {
+			return latest, err
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest, nil
+}
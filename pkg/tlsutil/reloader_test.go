@@ -0,0 +1,96 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// writeCert writes a self-signed certificate pair to dir.
+func writeCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := ioutil.WriteFile(certFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestServerConfigClientAuth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsutil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeCert(t, dir)
+
+	tests := []struct {
+		name       string
+		caFile     string
+		clientAuth tls.ClientAuthType
+		wantErr    bool
+	}{
+		{"no client auth without CA", "", tls.NoClientCert, false},
+		{"optional without CA", "", tls.VerifyClientCertIfGiven, true},
+		{"required without CA", "", tls.RequireAndVerifyClientCert, true},
+		{"required with CA", certFile, tls.RequireAndVerifyClientCert, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewReloader(certFile, keyFile, tt.caFile, zap.NewNop())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = r.ServerConfig(tt.clientAuth)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ServerConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			_, err = r.HTTPServerConfig(tt.clientAuth)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HTTPServerConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Package codec reads and writes todos in the file formats supported by
+// import and export: JSON Lines, CSV and todo.txt.
+package codec
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+)
+
+// Formats.
+const (
+	JSONL   = "jsonl"
+	CSV     = "csv"
+	TodoTxt = "todotxt"
+)
+
+// Formats are the supported formats.
+var Formats = []string{JSONL, CSV, TodoTxt}
+
+// FormatFromPath infers the format of a file from its extension.
+func FormatFromPath(path string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".json":
+		return JSONL, true
+	case ".csv":
+		return CSV, true
+	case ".txt":
+		return TodoTxt, true
+	}
+	return "", false
+}
+
+// LineError is a line that couldn't be decoded. Decoding can go on
+// after it.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Decoder reads todos.
+type Decoder interface {
+	// Decode returns the next todo and its line, io.EOF when there are
+	// no more, or a *LineError for a line that isn't a todo.
+	Decode() (todo.Todo, int, error)
+}
+
+// DecodeOptions configure a Decoder.
+type DecodeOptions struct {
+	// Columns maps CSV header names to the fields of todos, such as
+	// "due" to "reminder". Headers that aren't mapped are matched with
+	// the field names, ignoring case.
+	Columns map[string]string
+	// Location is the timezone of dates and times without one, time.Local
+	// if nil.
+	Location *time.Location
+}
+
+// NewDecoder returns a Decoder reading todos in format from r.
+func NewDecoder(format string, r io.Reader, opts DecodeOptions) (Decoder, error) {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+
+	switch format {
+	case JSONL:
+		return newJSONLDecoder(r), nil
+	case CSV:
+		return newCSVDecoder(r, opts)
+	case TodoTxt:
+		return newTodoTxtDecoder(r, opts.Location), nil
+	}
+	return nil, unknownFormat(format)
+}
+
+// Encoder writes todos.
+type Encoder interface {
+	Encode(t todo.Todo) error
+	// Flush writes any buffered data, and must be called after the last
+	// todo is encoded.
+	Flush() error
+}
+
+// NewEncoder returns an Encoder writing todos in format to w, with dates
+// in loc, time.Local if nil.
+func NewEncoder(format string, w io.Writer, loc *time.Location) (Encoder, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	switch format {
+	case JSONL:
+		return newJSONLEncoder(w, loc), nil
+	case CSV:
+		return newCSVEncoder(w, loc), nil
+	case TodoTxt:
+		return newTodoTxtEncoder(w, loc), nil
+	}
+	return nil, unknownFormat(format)
+}
+
+func unknownFormat(format string) error {
+	return fmt.Errorf("format must be one of %s, got %q", strings.Join(Formats, ", "), format)
+}
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02 15:04"
+)
+
+// parseTime parses an RFC 3339 time, or a date or date and time without
+// seconds in loc.
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{dateTimeLayout, dateLayout} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not an RFC 3339 time, %s or %s", s, dateTimeLayout, dateLayout)
+}
+
+// parsePriority accepts priorities in either case.
+func parsePriority(s string) (string, error) {
+	p := strings.ToUpper(strings.TrimSpace(s))
+	if !todo.ValidPriority(p) {
+		return "", fmt.Errorf("priority must be a letter from A to Z, got %q", s)
+	}
+	return p, nil
+}
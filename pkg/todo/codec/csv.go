@@ -0,0 +1,176 @@
+package codec
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+)
+
+// csvFields are the fields in CSV files, in the order they are exported.
+// The id and updated_at are ignored on import.
+var csvFields = []string{"id", "title", "description", "priority", "reminder", "created_at", "updated_at"}
+
+// csvDecoder reads a CSV file with a header. Its line numbers are record
+// numbers, which differ from the line numbers of the file if values span
+// several lines.
+type csvDecoder struct {
+	r *csv.Reader
+	// columns holds the index of the column of each field.
+	columns map[string]int
+	loc     *time.Location
+	line    int
+}
+
+func newCSVDecoder(r io.Reader, opts DecodeOptions) (*csvDecoder, error) {
+	d := &csvDecoder{r: csv.NewReader(r), loc: opts.Location, line: 1}
+	d.r.FieldsPerRecord = -1
+
+	header, err := d.r.Read()
+	if err == io.EOF {
+		return nil, errors.New("missing CSV header")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %v", err)
+	}
+
+	mapping := make(map[string]string, len(opts.Columns))
+	for col, field := range opts.Columns {
+		if !isCSVField(field) {
+			return nil, fmt.Errorf("column %q is mapped to unknown field %q, fields are %s",
+				col, field, strings.Join(csvFields, ", "))
+		}
+		mapping[strings.ToLower(strings.TrimSpace(col))] = field
+	}
+
+	d.columns = make(map[string]int)
+	for i, col := range header {
+		col = strings.ToLower(strings.TrimSpace(col))
+		field, ok := mapping[col]
+		if !ok {
+			field = col
+		}
+		if !isCSVField(field) {
+			// Files from other tools often have columns we have no
+			// field for.
+			continue
+		}
+		if _, ok := d.columns[field]; ok {
+			return nil, fmt.Errorf("several columns are mapped to %s", field)
+		}
+		d.columns[field] = i
+	}
+	if _, ok := d.columns["title"]; !ok {
+		return nil, fmt.Errorf("no column is mapped to title, the header is %s", strings.Join(header, ","))
+	}
+
+	return d, nil
+}
+
+func isCSVField(field string) bool {
+	for _, f := range csvFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *csvDecoder) Decode() (todo.Todo, int, error) {
+	record, err := d.r.Read()
+	if err == io.EOF {
+		return todo.Todo{}, d.line, io.EOF
+	}
+	d.line++
+	if err != nil {
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			return todo.Todo{}, d.line, &LineError{d.line, perr.Err}
+		}
+		return todo.Todo{}, d.line, err
+	}
+
+	t, err := d.todo(record)
+	if err != nil {
+		return todo.Todo{}, d.line, &LineError{d.line, err}
+	}
+	return t, d.line, nil
+}
+
+func (d *csvDecoder) todo(record []string) (todo.Todo, error) {
+	value := func(field string) string {
+		i, ok := d.columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	t := todo.Todo{Title: value("title"), Description: value("description")}
+
+	var err error
+	if t.Priority, err = parsePriority(value("priority")); err != nil {
+		return todo.Todo{}, err
+	}
+	if s := value("reminder"); s != "" {
+		if t.Reminder, err = parseTime(s, d.loc); err != nil {
+			return todo.Todo{}, fmt.Errorf("invalid reminder: %v", err)
+		}
+	}
+	if s := value("created_at"); s != "" {
+		if t.CreatedAt, err = parseTime(s, d.loc); err != nil {
+			return todo.Todo{}, fmt.Errorf("invalid created_at: %v", err)
+		}
+	}
+
+	return t, nil
+}
+
+type csvEncoder struct {
+	w           *csv.Writer
+	loc         *time.Location
+	wroteHeader bool
+}
+
+func newCSVEncoder(w io.Writer, loc *time.Location) *csvEncoder {
+	return &csvEncoder{w: csv.NewWriter(w), loc: loc}
+}
+
+func (e *csvEncoder) Encode(t todo.Todo) error {
+	e.writeHeader()
+	return e.w.Write([]string{
+		strconv.FormatUint(uint64(t.ID), 10),
+		t.Title,
+		t.Description,
+		t.Priority,
+		e.time(t.Reminder),
+		e.time(t.CreatedAt),
+		e.time(t.UpdatedAt),
+	})
+}
+
+func (e *csvEncoder) time(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(e.loc).Format(time.RFC3339)
+}
+
+// Flush writes the header even if there were no todos.
+func (e *csvEncoder) Flush() error {
+	e.writeHeader()
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) writeHeader() {
+	if !e.wroteHeader {
+		e.w.Write(csvFields)
+		e.wroteHeader = true
+	}
+}
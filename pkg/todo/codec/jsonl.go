@@ -0,0 +1,106 @@
+package codec
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+)
+
+// maxLineLen is the longest line a decoder accepts.
+const maxLineLen = 1 << 20
+
+// jsonTodo is a todo in JSON Lines, with the field names of the REST API.
+// The id and updated_at are exported but ignored on import.
+type jsonTodo struct {
+	ID          uint       `json:"id,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Priority    string     `json:"priority,omitempty"`
+	Reminder    *time.Time `json:"reminder,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+type jsonlDecoder struct {
+	s    *bufio.Scanner
+	line int
+}
+
+func newJSONLDecoder(r io.Reader) *jsonlDecoder {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, maxLineLen)
+	return &jsonlDecoder{s: s}
+}
+
+func (d *jsonlDecoder) Decode() (todo.Todo, int, error) {
+	for d.s.Scan() {
+		d.line++
+		b := d.s.Bytes()
+		if strings.TrimSpace(string(b)) == "" {
+			continue
+		}
+
+		var jt jsonTodo
+		if err := json.Unmarshal(b, &jt); err != nil {
+			return todo.Todo{}, d.line, &LineError{d.line, fmt.Errorf("invalid JSON: %v", err)}
+		}
+		p, err := parsePriority(jt.Priority)
+		if err != nil {
+			return todo.Todo{}, d.line, &LineError{d.line, err}
+		}
+
+		t := todo.Todo{Title: jt.Title, Description: jt.Description, Priority: p}
+		if jt.Reminder != nil {
+			t.Reminder = *jt.Reminder
+		}
+		if jt.CreatedAt != nil {
+			t.CreatedAt = *jt.CreatedAt
+		}
+		return t, d.line, nil
+	}
+
+	if err := d.s.Err(); err != nil {
+		return todo.Todo{}, d.line + 1, fmt.Errorf("line %d: %v", d.line+1, err)
+	}
+	return todo.Todo{}, d.line, io.EOF
+}
+
+type jsonlEncoder struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+	loc *time.Location
+}
+
+func newJSONLEncoder(w io.Writer, loc *time.Location) *jsonlEncoder {
+	bw := bufio.NewWriter(w)
+	return &jsonlEncoder{w: bw, enc: json.NewEncoder(bw), loc: loc}
+}
+
+func (e *jsonlEncoder) Encode(t todo.Todo) error {
+	return e.enc.Encode(jsonTodo{
+		ID:          t.ID,
+		Title:       t.Title,
+		Description: t.Description,
+		Priority:    t.Priority,
+		Reminder:    e.time(t.Reminder),
+		CreatedAt:   e.time(t.CreatedAt),
+		UpdatedAt:   e.time(t.UpdatedAt),
+	})
+}
+
+func (e *jsonlEncoder) time(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.In(e.loc)
+	return &t
+}
+
+func (e *jsonlEncoder) Flush() error {
+	return e.w.Flush()
+}
@@ -0,0 +1,125 @@
+package codec
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+)
+
+// todotxtDecoder reads the todo.txt format, one task per line:
+//
+//	(A) 2020-01-02 Call mom +family @phone due:2020-01-05
+//
+// The priority and creation date are optional. The due date becomes the
+// reminder, at midnight, and the rest of the line, +projects and
+// @contexts included, becomes the title. Completed tasks, starting with
+// "x ", are rejected, as todos can't be completed.
+type todotxtDecoder struct {
+	s    *bufio.Scanner
+	loc  *time.Location
+	line int
+}
+
+func newTodoTxtDecoder(r io.Reader, loc *time.Location) *todotxtDecoder {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, maxLineLen)
+	return &todotxtDecoder{s: s, loc: loc}
+}
+
+func (d *todotxtDecoder) Decode() (todo.Todo, int, error) {
+	for d.s.Scan() {
+		d.line++
+		line := strings.TrimSpace(d.s.Text())
+		if line == "" {
+			continue
+		}
+
+		t, err := d.todo(line)
+		if err != nil {
+			return todo.Todo{}, d.line, &LineError{d.line, err}
+		}
+		return t, d.line, nil
+	}
+
+	if err := d.s.Err(); err != nil {
+		return todo.Todo{}, d.line + 1, fmt.Errorf("line %d: %v", d.line+1, err)
+	}
+	return todo.Todo{}, d.line, io.EOF
+}
+
+func (d *todotxtDecoder) todo(line string) (todo.Todo, error) {
+	if strings.HasPrefix(line, "x ") {
+		return todo.Todo{}, errors.New("completed tasks can't be imported")
+	}
+
+	var t todo.Todo
+	if len(line) >= 4 && line[0] == '(' && line[2] == ')' && line[3] == ' ' &&
+		line[1] >= 'A' && line[1] <= 'Z' {
+		t.Priority = line[1:2]
+		line = strings.TrimSpace(line[4:])
+	}
+
+	words := strings.Fields(line)
+	if len(words) > 0 {
+		if c, err := time.ParseInLocation(dateLayout, words[0], d.loc); err == nil {
+			t.CreatedAt = c
+			words = words[1:]
+		}
+	}
+
+	title := words[:0]
+	for _, w := range words {
+		if !strings.HasPrefix(w, "due:") {
+			title = append(title, w)
+			continue
+		}
+		due, err := time.ParseInLocation(dateLayout, strings.TrimPrefix(w, "due:"), d.loc)
+		if err != nil {
+			return todo.Todo{}, fmt.Errorf("invalid due date %q, expected %s", w, dateLayout)
+		}
+		t.Reminder = due
+	}
+	t.Title = strings.Join(title, " ")
+
+	return t, nil
+}
+
+// todotxtEncoder writes todos in the todo.txt format. It has no place for
+// descriptions, which are left out, and its due dates drop the time of
+// reminders.
+type todotxtEncoder struct {
+	w   *bufio.Writer
+	loc *time.Location
+}
+
+func newTodoTxtEncoder(w io.Writer, loc *time.Location) *todotxtEncoder {
+	return &todotxtEncoder{w: bufio.NewWriter(w), loc: loc}
+}
+
+func (e *todotxtEncoder) Encode(t todo.Todo) error {
+	var b strings.Builder
+	if t.Priority != "" {
+		fmt.Fprintf(&b, "(%s) ", t.Priority)
+	}
+	if !t.CreatedAt.IsZero() {
+		b.WriteString(t.CreatedAt.In(e.loc).Format(dateLayout) + " ")
+	}
+	// Titles can't span lines.
+	b.WriteString(strings.Join(strings.Fields(t.Title), " "))
+	if !t.Reminder.IsZero() {
+		b.WriteString(" due:" + t.Reminder.In(e.loc).Format(dateLayout))
+	}
+	b.WriteByte('\n')
+
+	_, err := e.w.WriteString(b.String())
+	return err
+}
+
+func (e *todotxtEncoder) Flush() error {
+	return e.w.Flush()
+}
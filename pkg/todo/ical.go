@@ -0,0 +1,133 @@
+package todo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icalProdID = "-//prototodo//todos//EN"
+	// icalUIDDomain makes the ids of todos globally unique UIDs.
+	icalUIDDomain = "prototodo"
+	icalTime      = "20060102T150405Z"
+	// icalLineLen is the longest a content line may be, in octets, before
+	// it must be folded.
+	icalLineLen = 75
+)
+
+// WriteCalendar writes tt as an iCalendar (RFC 5545) calendar named name,
+// with a VTODO for each todo, due at its reminder, and a VALARM that
+// displays its title at the reminder. The output only depends on tt, so
+// its hash can serve as an ETag.
+func WriteCalendar(w io.Writer, name string, tt []Todo) error {
+	cw := &calendarWriter{w: bufio.NewWriter(w)}
+
+	cw.line("BEGIN", "VCALENDAR")
+	cw.line("VERSION", "2.0")
+	cw.line("PRODID", icalProdID)
+	cw.line("CALSCALE", "GREGORIAN")
+	if name != "" {
+		cw.line("X-WR-CALNAME", escapeText(name))
+	}
+	for _, t := range tt {
+		cw.todo(t)
+	}
+	cw.line("END", "VCALENDAR")
+
+	return cw.w.Flush()
+}
+
+type calendarWriter struct {
+	w *bufio.Writer
+}
+
+func (cw *calendarWriter) todo(t Todo) {
+	cw.line("BEGIN", "VTODO")
+	cw.line("UID", fmt.Sprintf("%d@%s", t.ID, icalUIDDomain))
+	// DTSTAMP is required, using the time of the last change rather than
+	// now keeps the calendar the same until a todo changes.
+	cw.line("DTSTAMP", icalTimeOf(t.UpdatedAt))
+	if !t.CreatedAt.IsZero() {
+		cw.line("CREATED", icalTimeOf(t.CreatedAt))
+	}
+	if !t.UpdatedAt.IsZero() {
+		cw.line("LAST-MODIFIED", icalTimeOf(t.UpdatedAt))
+	}
+	cw.line("SUMMARY", escapeText(t.Title))
+	if t.Description != "" {
+		cw.line("DESCRIPTION", escapeText(t.Description))
+	}
+	if p := icalPriority(t.Priority); p != 0 {
+		cw.line("PRIORITY", fmt.Sprint(p))
+	}
+	cw.line("STATUS", "NEEDS-ACTION")
+
+	if !t.Reminder.IsZero() {
+		due := icalTimeOf(t.Reminder)
+		cw.line("DUE", due)
+		cw.line("BEGIN", "VALARM")
+		cw.line("ACTION", "DISPLAY")
+		cw.line("TRIGGER;VALUE=DATE-TIME", due)
+		cw.line("DESCRIPTION", escapeText(t.Title))
+		cw.line("END", "VALARM")
+	}
+	cw.line("END", "VTODO")
+}
+
+// line writes a content line, folding it so that no line is longer than
+// icalLineLen octets, without splitting UTF-8 sequences.
+func (cw *calendarWriter) line(name, value string) {
+	s := name + ":" + value
+	limit := icalLineLen
+	for len(s) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+		cw.w.WriteString(s[:i])
+		cw.w.WriteString("\r\n ")
+		s = s[i:]
+		// The space that continues a folded line counts towards it.
+		limit = icalLineLen - 1
+	}
+	cw.w.WriteString(s)
+	cw.w.WriteString("\r\n")
+}
+
+// icalTimeOf formats t in UTC, the zero time as the Unix epoch.
+func icalTimeOf(t time.Time) string {
+	if t.IsZero() {
+		t = time.Unix(0, 0)
+	}
+	return t.UTC().Format(icalTime)
+}
+
+// icalPriority maps priorities A to H to the iCalendar priorities 1,
+// the highest, to 8 and the rest to 9, the lowest. 0 is undefined.
+func icalPriority(p string) int {
+	if !ValidPriority(p) || p == "" {
+		return 0
+	}
+	if n := int(p[0]-'A') + 1; n < 9 {
+		return n
+	}
+	return 9
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// escapeText escapes a TEXT value.
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
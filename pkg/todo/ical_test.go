@@ -0,0 +1,153 @@
+package todo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestWriteCalendar(t *testing.T) {
+	created := time.Date(2026, 3, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	updated := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	todo := func(title string) Todo {
+		t := Todo{Title: title}
+		t.ID, t.CreatedAt, t.UpdatedAt = 7, created, updated
+		return t
+	}
+
+	tests := []struct {
+		name string
+		todo Todo
+		// want are lines of the unfolded output, notWant are prefixes of
+		// lines that mustn't be in it.
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "times in UTC",
+			todo: todo("milk"),
+			want: []string{
+				"UID:7@prototodo",
+				"DTSTAMP:20260302T100000Z",
+				"CREATED:20260301T083000Z",
+				"LAST-MODIFIED:20260302T100000Z",
+				"SUMMARY:milk",
+				"STATUS:NEEDS-ACTION",
+			},
+			notWant: []string{"DESCRIPTION", "PRIORITY", "DUE", "BEGIN:VALARM"},
+		},
+		{
+			name:    "zero times",
+			todo:    Todo{Title: "milk"},
+			want:    []string{"DTSTAMP:19700101T000000Z"},
+			notWant: []string{"CREATED", "LAST-MODIFIED"},
+		},
+		{
+			name: "escaped text",
+			todo: Todo{Title: `a\b;c,d`, Description: "one\r\ntwo\nthree\rfour"},
+			want: []string{`SUMMARY:a\\b\;c\,d`, `DESCRIPTION:one\ntwo\nthree\nfour`},
+		},
+		{
+			name: "reminder",
+			todo: Todo{Title: "milk", Reminder: updated},
+			want: []string{
+				"DUE:20260302T100000Z",
+				"BEGIN:VALARM",
+				"ACTION:DISPLAY",
+				"TRIGGER;VALUE=DATE-TIME:20260302T100000Z",
+				"DESCRIPTION:milk",
+				"END:VALARM",
+			},
+		},
+		{name: "highest priority", todo: Todo{Title: "milk", Priority: "A"}, want: []string{"PRIORITY:1"}},
+		{name: "lowest mapped priority", todo: Todo{Title: "milk", Priority: "H"}, want: []string{"PRIORITY:8"}},
+		{name: "priority past H", todo: Todo{Title: "milk", Priority: "Z"}, want: []string{"PRIORITY:9"}},
+		{name: "invalid priority", todo: Todo{Title: "milk", Priority: "1"}, notWant: []string{"PRIORITY"}},
+		{
+			name: "long ASCII title",
+			todo: Todo{Title: strings.Repeat("milk ", 40)},
+			want: []string{"SUMMARY:" + strings.Repeat("milk ", 40)},
+		},
+		{
+			// Two-byte runes put the fold points inside runes.
+			name: "long UTF-8 title",
+			todo: Todo{Title: strings.Repeat("é", 100)},
+			want: []string{"SUMMARY:" + strings.Repeat("é", 100)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteCalendar(&buf, "Todos", []Todo{tt.todo}); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.String()
+
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output doesn't end with CRLF: %q", out)
+			}
+			for _, l := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+				if len(l) > icalLineLen {
+					t.Errorf("line is %d octets long, longer than %d: %q", len(l), icalLineLen, l)
+				}
+				if !utf8.ValidString(l) {
+					t.Errorf("line splits a UTF-8 sequence: %q", l)
+				}
+			}
+
+			lines := strings.Split(strings.ReplaceAll(out, "\r\n ", ""), "\r\n")
+			if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-2] != "END:VCALENDAR" {
+				t.Errorf("output isn't a calendar:\n%s", out)
+			}
+			has := make(map[string]bool)
+			for _, l := range lines {
+				has[l] = true
+			}
+			for _, w := range tt.want {
+				if !has[w] {
+					t.Errorf("missing line %q in:\n%s", w, out)
+				}
+			}
+			for _, n := range tt.notWant {
+				for _, l := range lines {
+					if strings.HasPrefix(l, n+":") || strings.HasPrefix(l, n+";") || l == n {
+						t.Errorf("unexpected line %q", l)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWriteCalendarFoldsAtLimit(t *testing.T) {
+	var buf bytes.Buffer
+	// SUMMARY: and 67 characters make a line of exactly 75 octets, one
+	// more character must be folded onto a continuation line.
+	for _, n := range []int{67, 68} {
+		buf.Reset()
+		if err := WriteCalendar(&buf, "", []Todo{{Title: strings.Repeat("a", n)}}); err != nil {
+			t.Fatal(err)
+		}
+		folded := strings.Contains(buf.String(), "\r\n a")
+		if folded != (n > 67) {
+			t.Errorf("title of %d characters folded = %v", n, folded)
+		}
+	}
+
+	// Continuation lines start with a space that counts towards the limit.
+	buf.Reset()
+	if err := WriteCalendar(&buf, "", []Todo{{Title: strings.Repeat("a", 67+74+10)}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range strings.Split(buf.String(), "\r\n") {
+		if strings.HasPrefix(l, " ") && len(l) > icalLineLen {
+			t.Errorf("continuation line is %d octets long", len(l))
+		}
+	}
+	if !strings.Contains(buf.String(), "\r\n "+strings.Repeat("a", 74)+"\r\n "+strings.Repeat("a", 10)+"\r\n") {
+		t.Errorf("continuation lines aren't filled to the limit:\n%s", buf.String())
+	}
+}
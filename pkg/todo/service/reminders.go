@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+	"go.uber.org/zap"
+)
+
+// reminderInterval is how often reminders that came up are looked for.
+const reminderInterval = 10 * time.Second
+
+// Reminders publishes a todo.reminder event when the reminder of a todo
+// comes up.
+type Reminders struct {
+	r Repository
+	p Publisher
+	l *zap.Logger
+}
+
+// NewReminders creates Reminders publishing the reminders in r to p.
+func NewReminders(r Repository, p Publisher, l *zap.Logger) *Reminders {
+	return &Reminders{r: r, p: p, l: l}
+}
+
+// Run publishes the reminders that come up until stop is closed. Those
+// that came up before it started, or while the server was down, are
+// skipped.
+func (rm *Reminders) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(reminderInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		ctx := context.Background()
+		tt, err := rm.r.DueReminders(ctx, last, now)
+		if err != nil {
+			// The same window is tried again on the next tick.
+			rm.l.Error("failed to fetch due reminders", zap.Error(err))
+			continue
+		}
+		for _, t := range tt {
+			rm.p.Publish(ctx, todo.Event{
+				// Servers that find the same reminder publish the same
+				// event, which is only delivered once.
+				ID:        fmt.Sprintf("reminder-%d-%d", t.ID, t.Reminder.Unix()),
+				Type:      todo.EventReminder,
+				OwnerID:   t.OwnerID,
+				Todo:      t,
+				CreatedAt: now,
+			})
+		}
+		rm.l.Debug("published due reminders", zap.Int("count", len(tt)))
+		last = now
+	}
+}
@@ -2,9 +2,15 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
 
+	"github.com/dikaeinstein/prototodo/pkg/auth"
 	"github.com/dikaeinstein/prototodo/pkg/protocol/grpc"
 	"github.com/dikaeinstein/prototodo/pkg/todo"
+	"github.com/dikaeinstein/prototodo/pkg/tracing"
 )
 
 // Repository provides access to the todo data store.
@@ -14,39 +20,125 @@ type Repository interface {
 	Create(ctx context.Context, t todo.Todo) (todo.Todo, error)
 	Delete(ctx context.Context, id uint) (uint, error)
 	Update(ctx context.Context, id uint, t todo.Todo) (todo.Todo, error)
+	Search(ctx context.Context, query string, limit, offset int) ([]todo.SearchResult, error)
+	DueReminders(ctx context.Context, from, to time.Time) ([]todo.Todo, error)
+}
+
+// Publisher notifies subscribers, such as webhooks, of events. It handles
+// its own failures, which mustn't fail the change that caused the event.
+type Publisher interface {
+	Publish(ctx context.Context, e todo.Event)
 }
 
 // New creates a todo service with the necessary dependencies.
 // This contains the core business logic to operate on todo items.
-func New(r Repository) grpc.Service {
-	return &service{r}
+// Changes to todos are published to p, whichever transport made them.
+func New(r Repository, p Publisher) grpc.Service {
+	return &service{r, p}
 }
 
 type service struct {
 	r Repository
+	p Publisher
 }
 
 func (s service) Create(ctx context.Context, t todo.Todo) (todo.Todo, error) {
-	return s.r.Create(ctx, t)
+	ctx, span := tracing.Tracer().Start(ctx, "service.Create")
+	defer span.End()
+
+	t, err := s.r.Create(ctx, t)
+	if err != nil {
+		return t, err
+	}
+	s.publish(ctx, todo.EventCreated, t.OwnerID, t)
+
+	return t, nil
 }
 
 func (s service) Delete(ctx context.Context, id uint) (uint, error) {
-	return s.r.Delete(ctx, id)
+	ctx, span := tracing.Tracer().Start(ctx, "service.Delete")
+	defer span.End()
+
+	id, err := s.r.Delete(ctx, id)
+	if err != nil {
+		return id, err
+	}
+	owner := auth.FromContext(ctx).Subject
+	deleted := todo.Todo{OwnerID: owner}
+	deleted.ID = id
+	s.publish(ctx, todo.EventDeleted, owner, deleted)
+
+	return id, nil
 }
 
 func (s service) Read(ctx context.Context, id uint) (todo.Todo, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.Read")
+	defer span.End()
+
 	return s.r.GetByID(ctx, id)
 }
 
 func (s service) ReadAll(ctx context.Context) (chan todo.Todo, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.ReadAll")
+
 	c, err := s.r.GetAll(ctx)
 	if err != nil {
+		span.End()
 		return nil, err
 	}
 
-	return c, err
+	// The span covers reading the todos, so it ends once they're drained.
+	out := make(chan todo.Todo)
+	go func() {
+		defer span.End()
+		defer close(out)
+		for t := range c {
+			select {
+			case <-ctx.Done():
+				return
+			case out <- t:
+			}
+		}
+	}()
+
+	return out, nil
 }
 
 func (s service) Update(ctx context.Context, todoID uint, t todo.Todo) (todo.Todo, error) {
-	return s.r.Update(ctx, todoID, t)
+	ctx, span := tracing.Tracer().Start(ctx, "service.Update")
+	defer span.End()
+
+	t, err := s.r.Update(ctx, todoID, t)
+	if err != nil {
+		return t, err
+	}
+	s.publish(ctx, todo.EventUpdated, t.OwnerID, t)
+
+	return t, nil
+}
+
+func (s service) Search(ctx context.Context, query string, limit, offset int) ([]todo.SearchResult, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.Search")
+	defer span.End()
+
+	return s.r.Search(ctx, query, limit, offset)
+}
+
+func (s service) publish(ctx context.Context, typ, owner string, t todo.Todo) {
+	s.p.Publish(ctx, todo.Event{
+		ID:        newEventID(),
+		Type:      typ,
+		OwnerID:   owner,
+		Todo:      t,
+		CreatedAt: time.Now(),
+	})
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// Deliveries are unique per event id, so it must not repeat.
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
 }
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// GormLogger writes the messages of gorm, such as the SQL statements it
+// runs in log mode, to a zap logger at debug level. Only the statements
+// are logged, not the values bound to them, which hold user data.
+type GormLogger struct {
+	L *zap.Logger
+}
+
+// Print implements gorm's logger interface.
+func (g GormLogger) Print(v ...interface{}) {
+	if len(v) >= 6 && v[0] == "sql" {
+		d, _ := v[2].(time.Duration)
+		vars, _ := v[4].([]interface{})
+		rows, _ := v[5].(int64)
+		g.L.Debug("query",
+			zap.String("sql", fmt.Sprint(v[3])),
+			zap.Int("vars", len(vars)),
+			zap.Duration("duration", d),
+			zap.Int64("rows", rows),
+		)
+		return
+	}
+	if len(v) > 2 {
+		v = v[2:]
+	}
+	g.L.Debug(fmt.Sprint(v...))
+}
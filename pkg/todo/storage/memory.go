@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+)
+
+// MemoryStore is an in-memory todo data store. It is meant for development
+// and tests where running postgres isn't practical.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	todos  map[uint]todo.Todo
+	nextID uint
+	keys   map[keyID]todo.IdempotencyKey
+
+	webhooks       map[uint]todo.Webhook
+	nextWebhookID  uint
+	deliveries     map[uint]todo.WebhookDelivery
+	nextDeliveryID uint
+}
+
+// keyID identifies the idempotency key of a caller.
+type keyID struct {
+	owner string
+	key   string
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		todos:  make(map[uint]todo.Todo),
+		nextID: 1,
+		keys:   make(map[keyID]todo.IdempotencyKey),
+
+		webhooks:       make(map[uint]todo.Webhook),
+		nextWebhookID:  1,
+		deliveries:     make(map[uint]todo.WebhookDelivery),
+		nextDeliveryID: 1,
+	}
+}
+
+// GetAll fetches all todo items owned by the caller from the in-memory data store.
+func (m *MemoryStore) GetAll(ctx context.Context) (chan todo.Todo, error) {
+	tt := m.sorted(ownerID(ctx))
+	c := make(chan todo.Todo)
+
+	go func() {
+		defer close(c)
+		for _, t := range tt {
+			select {
+			case <-ctx.Done():
+				return
+			case c <- t:
+			}
+		}
+	}()
+
+	return c, nil
+}
+
+// GetByID fetches one todo item owned by the caller from the in-memory data store using its id.
+func (m *MemoryStore) GetByID(ctx context.Context, id uint) (todo.Todo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, ok := m.todos[id]
+	if !ok || t.OwnerID != ownerID(ctx) {
+		return todo.Todo{}, ErrNotFound
+	}
+
+	return t, nil
+}
+
+// Create saves the todo into the in-memory data store, owned by the caller.
+func (m *MemoryStore) Create(ctx context.Context, t todo.Todo) (todo.Todo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	t.ID = m.nextID
+	t.OwnerID = ownerID(ctx)
+	// Imported todos keep their creation time, like they do in postgres.
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = now
+	}
+	t.UpdatedAt = now
+	m.todos[t.ID] = t
+	m.nextID++
+
+	return t, nil
+}
+
+// Delete removes a todo item owned by the caller from the in-memory data store.
+func (m *MemoryStore) Delete(ctx context.Context, id uint) (uint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t, ok := m.todos[id]; !ok || t.OwnerID != ownerID(ctx) {
+		return id, ErrNotFound
+	}
+	delete(m.todos, id)
+
+	return id, nil
+}
+
+// Update updates a todo item owned by the caller with the non-zero fields
+// of attrs in the in-memory data store.
+func (m *MemoryStore) Update(ctx context.Context, todoID uint, attrs todo.Todo) (todo.Todo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.todos[todoID]
+	if !ok || t.OwnerID != ownerID(ctx) {
+		return todo.Todo{}, ErrNotFound
+	}
+
+	if attrs.Title != "" {
+		t.Title = attrs.Title
+	}
+	if attrs.Description != "" {
+		t.Description = attrs.Description
+	}
+	if !attrs.Reminder.IsZero() {
+		t.Reminder = attrs.Reminder
+	}
+	if attrs.Priority != "" {
+		t.Priority = attrs.Priority
+	}
+	t.UpdatedAt = time.Now()
+	m.todos[todoID] = t
+
+	return t, nil
+}
+
+// DueReminders fetches the todo items of all users with a reminder after
+// from and at or before to.
+func (m *MemoryStore) DueReminders(ctx context.Context, from, to time.Time) ([]todo.Todo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tt := make([]todo.Todo, 0)
+	for _, t := range m.todos {
+		if t.Reminder.After(from) && !t.Reminder.After(to) {
+			tt = append(tt, t)
+		}
+	}
+	sort.Slice(tt, func(i, j int) bool { return tt[i].ID < tt[j].ID })
+
+	return tt, nil
+}
+
+// Search matches all the terms of the query against the words of the title and description
+// of the caller's todo items, ranking title matches above description matches. Words aren't
+// stemmed as they are in postgres.
+func (m *MemoryStore) Search(ctx context.Context, query string, limit, offset int) ([]todo.SearchResult, error) {
+	terms := tokenize(query)
+	results := make([]todo.SearchResult, 0)
+	for _, t := range m.sorted(ownerID(ctx)) {
+		if r, ok := match(t, terms); ok {
+			results = append(results, r)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rank > results[j].Rank
+	})
+
+	if offset >= len(results) {
+		return []todo.SearchResult{}, nil
+	}
+	results = results[offset:]
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// ReserveIdempotencyKey records that the caller is making the request of k,
+// unless the caller made a request with the same key that hasn't expired,
+// in which case it returns that one and false.
+func (m *MemoryStore) ReserveIdempotencyKey(ctx context.Context, k todo.IdempotencyKey) (todo.IdempotencyKey, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	k.OwnerID = ownerID(ctx)
+	k.CreatedAt = time.Now()
+	k.Response = nil
+	k.Completed = false
+
+	id := keyID{k.OwnerID, k.Key}
+	if existing, ok := m.keys[id]; ok && existing.ExpiresAt.After(k.CreatedAt) {
+		return existing, false, nil
+	}
+	m.keys[id] = k
+
+	return k, true, nil
+}
+
+// CompleteIdempotencyKey stores the response to the request made with the
+// caller's key, to be replayed until expiresAt.
+func (m *MemoryStore) CompleteIdempotencyKey(ctx context.Context, key string, response []byte, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := keyID{ownerID(ctx), key}
+	k, ok := m.keys[id]
+	if !ok {
+		return nil
+	}
+	k.Response = response
+	k.Completed = true
+	k.ExpiresAt = expiresAt
+	m.keys[id] = k
+
+	return nil
+}
+
+// ReleaseIdempotencyKey frees the caller's key after the request made with
+// it failed, so that it can be retried.
+func (m *MemoryStore) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := keyID{ownerID(ctx), key}
+	if k, ok := m.keys[id]; ok && !k.Completed {
+		delete(m.keys, id)
+	}
+
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes the keys of all callers that expired
+// by now and returns how many there were.
+func (m *MemoryStore) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	for id, k := range m.keys {
+		if !k.ExpiresAt.After(now) {
+			delete(m.keys, id)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// sorted returns a snapshot of the todo items of owner ordered by id.
+func (m *MemoryStore) sorted(owner string) []todo.Todo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tt := make([]todo.Todo, 0, len(m.todos))
+	for _, t := range m.todos {
+		if t.OwnerID == owner {
+			tt = append(tt, t)
+		}
+	}
+	sort.Slice(tt, func(i, j int) bool { return tt[i].ID < tt[j].ID })
+
+	return tt
+}
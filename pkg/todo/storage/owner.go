@@ -0,0 +1,13 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/dikaeinstein/prototodo/pkg/auth"
+)
+
+// ownerID returns the id of the user that owns the todo items
+// the caller in ctx is allowed to see.
+func ownerID(ctx context.Context) string {
+	return auth.FromContext(ctx).Subject
+}
@@ -3,10 +3,13 @@ package storage
 import (
 	"context"
 	"errors"
-	"log"
+	"time"
 
+	"github.com/dikaeinstein/prototodo/pkg/logger"
 	"github.com/dikaeinstein/prototodo/pkg/todo"
+	"github.com/dikaeinstein/prototodo/pkg/tracing"
 	"github.com/jinzhu/gorm"
+	"go.uber.org/zap"
 )
 
 // ErrNotFound represents error when a todo item is not found in the postgres data store.
@@ -22,9 +25,30 @@ func NewPostgresStore(db *gorm.DB) *PostgresStore {
 	return &PostgresStore{db}
 }
 
-// GetAll fetches all todo items from postgres data store.
+// Migrate creates the todos, idempotency_keys, webhooks and
+// webhook_deliveries tables and the full-text search column and index that
+// gorm's AutoMigrate can't express.
+func (p *PostgresStore) Migrate() error {
+	if err := p.DB.AutoMigrate(&todo.Todo{}, &todo.IdempotencyKey{}, &todo.Webhook{}, &todo.WebhookDelivery{}).Error; err != nil {
+		return err
+	}
+
+	// search_vector is kept up to date by postgres, so no write path
+	// needs to know about it.
+	return p.DB.Exec(`
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (
+				setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+				setweight(to_tsvector('english', coalesce(description, '')), 'B')
+			) STORED;
+		CREATE INDEX IF NOT EXISTS todos_search_vector_idx
+			ON todos USING GIN (search_vector);
+	`).Error
+}
+
+// GetAll fetches all todo items owned by the caller from postgres data store.
 func (p *PostgresStore) GetAll(ctx context.Context) (chan todo.Todo, error) {
-	rows, err := p.DB.Model(&todo.Todo{}).Select("*").Rows()
+	rows, err := p.owned(ctx).Model(&todo.Todo{}).Select("*").Rows()
 	if err != nil {
 		return nil, err
 	}
@@ -36,13 +60,15 @@ func (p *PostgresStore) GetAll(ctx context.Context) (chan todo.Todo, error) {
 		defer close(c)
 		for rows.Next() {
 			var t todo.Todo
-			p.DB.ScanRows(rows, &t)
+			if err := p.DB.ScanRows(rows, &t); err != nil {
+				logger.FromContext(ctx).Named("storage").Error("failed to scan todo", zap.Error(err))
+				return
+			}
 			select {
 			case <-ctx.Done():
-				log.Println(ctx.Err())
-				break
-			default:
-				c <- t
+				logger.FromContext(ctx).Named("storage").Info("stopped reading todos", zap.Error(ctx.Err()))
+				return
+			case c <- t:
 			}
 		}
 	}()
@@ -50,10 +76,10 @@ func (p *PostgresStore) GetAll(ctx context.Context) (chan todo.Todo, error) {
 	return c, rows.Err()
 }
 
-// GetByID fetches one todo item from the postgres data store using its id.
+// GetByID fetches one todo item owned by the caller from the postgres data store using its id.
 func (p *PostgresStore) GetByID(ctx context.Context, id uint) (todo.Todo, error) {
 	var t todo.Todo
-	if err := p.DB.First(&t, id).Error; err != nil {
+	if err := p.owned(ctx).First(&t, id).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			return t, ErrNotFound
 		}
@@ -63,45 +89,165 @@ func (p *PostgresStore) GetByID(ctx context.Context, id uint) (todo.Todo, error)
 	return t, nil
 }
 
-// Create saves the todo into the postgres data store.
+// Create saves the todo into the postgres data store, owned by the caller.
 func (p *PostgresStore) Create(ctx context.Context, t todo.Todo) (todo.Todo, error) {
-	if err := p.DB.Create(&t).Error; err != nil {
+	t.OwnerID = ownerID(ctx)
+	if err := p.db(ctx).Create(&t).Error; err != nil {
 		return t, err
 	}
 
 	return t, nil
 }
 
-// Delete removes a todo item from the postgres data store.
+// Delete removes a todo item owned by the caller from the postgres data store.
 func (p *PostgresStore) Delete(ctx context.Context, id uint) (uint, error) {
 	var t todo.Todo
-	if err := p.DB.First(&t, id).Error; err != nil {
+	if err := p.owned(ctx).First(&t, id).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			return id, ErrNotFound
 		}
 		return id, err
 	}
 
-	if err := p.DB.Delete(&t).Error; err != nil {
+	if err := p.db(ctx).Delete(&t).Error; err != nil {
 		return id, err
 	}
 
 	return id, nil
 }
 
-// Update updates a todo item with attrs in the postgres data store
+// Search runs a full-text search over the title and description of the caller's todo items,
+// returning at most limit results ordered by rank and skipping the first offset.
+func (p *PostgresStore) Search(ctx context.Context, query string, limit, offset int) ([]todo.SearchResult, error) {
+	// The snippets are escaped here rather than in SQL, so ts_headline marks
+	// the matches with control characters stripped from the text.
+	sels := startSel + stopSel
+	opts := `StartSel="` + startSel + `", StopSel="` + stopSel + `"`
+	rows, err := p.db(ctx).Raw(`
+		SELECT todos.*,
+			ts_rank(search_vector, q) AS rank,
+			ts_headline('english', translate(title, ?, ''), q, ? || ', HighlightAll=true') AS title_snippet,
+			ts_headline('english', translate(description, ?, ''), q, ? || ', MaxFragments=2') AS description_snippet
+		FROM todos, plainto_tsquery('english', ?) q
+		WHERE search_vector @@ q AND owner_id = ? AND deleted_at IS NULL
+		ORDER BY rank DESC, id
+		LIMIT ? OFFSET ?`, sels, opts, sels, opts, query, ownerID(ctx), limit, offset).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]todo.SearchResult, 0, limit)
+	for rows.Next() {
+		var r todo.SearchResult
+		if err := p.DB.ScanRows(rows, &r); err != nil {
+			return nil, err
+		}
+		r.TitleSnippet = markSnippet(r.TitleSnippet)
+		r.DescriptionSnippet = markSnippet(r.DescriptionSnippet)
+		results = append(results, r)
+	}
+
+	return results, rows.Err()
+}
+
+// Update updates a todo item owned by the caller with attrs in the postgres data store
 func (p *PostgresStore) Update(ctx context.Context, todoID uint, attrs todo.Todo) (todo.Todo, error) {
+	// The owner of a todo never changes.
+	attrs.OwnerID = ""
+
 	var t todo.Todo
-	if err := p.DB.First(&t, todoID).Error; err != nil {
+	if err := p.owned(ctx).First(&t, todoID).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			return t, ErrNotFound
 		}
 		return t, err
 	}
 
-	if err := p.DB.Model(&t).Updates(attrs).Error; err != nil {
+	if err := p.db(ctx).Model(&t).Updates(attrs).Error; err != nil {
 		return t, err
 	}
 
 	return t, nil
 }
+
+// DueReminders fetches the todo items of all users with a reminder after
+// from and at or before to.
+func (p *PostgresStore) DueReminders(ctx context.Context, from, to time.Time) ([]todo.Todo, error) {
+	tt := make([]todo.Todo, 0)
+	err := p.db(ctx).Where("reminder > ? AND reminder <= ?", from, to).Order("id").Find(&tt).Error
+	return tt, err
+}
+
+// ReserveIdempotencyKey records that the caller is making the request of k,
+// unless the caller made a request with the same key that hasn't expired,
+// in which case it returns that one and false.
+func (p *PostgresStore) ReserveIdempotencyKey(ctx context.Context, k todo.IdempotencyKey) (todo.IdempotencyKey, bool, error) {
+	k.OwnerID = ownerID(ctx)
+	k.CreatedAt = time.Now()
+	k.Response = nil
+	k.Completed = false
+
+	// The insert takes over an expired key, and does nothing if the key
+	// is in use, atomically.
+	res := p.db(ctx).Exec(`
+		INSERT INTO idempotency_keys (owner_id, key, method, request_hash, completed, created_at, expires_at)
+		VALUES (?, ?, ?, ?, false, ?, ?)
+		ON CONFLICT (owner_id, key) DO UPDATE SET
+			method = EXCLUDED.method,
+			request_hash = EXCLUDED.request_hash,
+			response = NULL,
+			completed = false,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at`,
+		k.OwnerID, k.Key, k.Method, k.RequestHash, k.CreatedAt, k.ExpiresAt)
+	if res.Error != nil {
+		return k, false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return k, true, nil
+	}
+
+	var existing todo.IdempotencyKey
+	if err := p.db(ctx).Where("owner_id = ? AND key = ?", k.OwnerID, k.Key).First(&existing).Error; err != nil {
+		return k, false, err
+	}
+
+	return existing, false, nil
+}
+
+// CompleteIdempotencyKey stores the response to the request made with the
+// caller's key, to be replayed until expiresAt.
+func (p *PostgresStore) CompleteIdempotencyKey(ctx context.Context, key string, response []byte, expiresAt time.Time) error {
+	return p.db(ctx).Model(&todo.IdempotencyKey{}).
+		Where("owner_id = ? AND key = ?", ownerID(ctx), key).
+		Updates(map[string]interface{}{"response": response, "completed": true, "expires_at": expiresAt}).Error
+}
+
+// ReleaseIdempotencyKey frees the caller's key after the request made with
+// it failed, so that it can be retried.
+func (p *PostgresStore) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	return p.db(ctx).
+		Where("owner_id = ? AND key = ? AND NOT completed", ownerID(ctx), key).
+		Delete(&todo.IdempotencyKey{}).Error
+}
+
+// DeleteExpiredIdempotencyKeys deletes the keys of all callers that expired
+// by now and returns how many there were.
+func (p *PostgresStore) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	res := p.db(ctx).Where("expires_at <= ?", now).Delete(&todo.IdempotencyKey{})
+	return res.RowsAffected, res.Error
+}
+
+// owned scopes queries to the todo items of the caller, so other users'
+// todos look like they don't exist.
+func (p *PostgresStore) owned(ctx context.Context) *gorm.DB {
+	return p.db(ctx).Where("owner_id = ?", ownerID(ctx))
+}
+
+// db returns the connection to query with on behalf of the request in ctx,
+// so that queries are traced as part of the request.
+func (p *PostgresStore) db(ctx context.Context) *gorm.DB {
+	return tracing.WithContext(p.DB, ctx)
+}
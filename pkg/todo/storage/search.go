@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+)
+
+const (
+	titleWeight       = 1.0
+	descriptionWeight = 0.4
+)
+
+// tokenize lower-cases s and splits it into words on anything that isn't
+// a letter or a digit.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// match reports whether all of the query terms occur in t as whole words,
+// like they must for plainto_tsquery, and ranks and highlights t the way
+// the postgres search does. Unlike postgres it doesn't stem words, so
+// "price" doesn't match "prices".
+func match(t todo.Todo, terms []string) (todo.SearchResult, bool) {
+	if len(terms) == 0 {
+		return todo.SearchResult{}, false
+	}
+	title, desc := tokenize(t.Title), tokenize(t.Description)
+	for _, term := range terms {
+		if countHits(title, []string{term}) == 0 && countHits(desc, []string{term}) == 0 {
+			return todo.SearchResult{}, false
+		}
+	}
+	titleHits := countHits(title, terms)
+	descHits := countHits(desc, terms)
+
+	return todo.SearchResult{
+		Todo:               t,
+		Rank:               titleWeight*float64(titleHits) + descriptionWeight*float64(descHits),
+		TitleSnippet:       highlight(t.Title, terms),
+		DescriptionSnippet: highlight(t.Description, terms),
+	}, true
+}
+
+func countHits(words, terms []string) int {
+	hits := 0
+	for _, w := range words {
+		for _, term := range terms {
+			if w == term {
+				hits++
+				break
+			}
+		}
+	}
+	return hits
+}
+
+// highlight HTML-escapes s and wraps its words that match one of the terms
+// in <b></b>.
+func highlight(s string, terms []string) string {
+	var b strings.Builder
+	word := make([]rune, 0)
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		w := string(word)
+		if countHits([]string{strings.ToLower(w)}, terms) > 0 {
+			b.WriteString("<b>" + html.EscapeString(w) + "</b>")
+		} else {
+			b.WriteString(html.EscapeString(w))
+		}
+		word = word[:0]
+	}
+
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word = append(word, r)
+			continue
+		}
+		flush()
+		b.WriteString(html.EscapeString(string(r)))
+	}
+	flush()
+
+	return b.String()
+}
+
+// Postgres wraps the matches in ts_headline in these, which can't be in
+// the text as it is stripped of them, so that markSnippet can escape the
+// text and then mark the matches.
+const (
+	startSel = "\x01"
+	stopSel  = "\x02"
+)
+
+// markSnippet HTML-escapes a ts_headline snippet and replaces startSel
+// and stopSel with <b></b>.
+func markSnippet(s string) string {
+	return strings.NewReplacer(startSel, "<b>", stopSel, "</b>").Replace(html.EscapeString(s))
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/auth"
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+)
+
+// testDatabaseEnv names the environment variable with the URL of the
+// database the postgres tests run against. They are skipped without it.
+const testDatabaseEnv = "PROTOTODO_TEST_DATABASE_URL"
+
+type searcher interface {
+	Create(ctx context.Context, t todo.Todo) (todo.Todo, error)
+	Search(ctx context.Context, query string, limit, offset int) ([]todo.SearchResult, error)
+}
+
+func TestMemoryStoreSearch(t *testing.T) {
+	testSearch(t, NewMemoryStore())
+}
+
+func TestPostgresStoreSearch(t *testing.T) {
+	url := os.Getenv(testDatabaseEnv)
+	if url == "" {
+		t.Skipf("%s is not set", testDatabaseEnv)
+	}
+	db, err := gorm.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	p := NewPostgresStore(db)
+	if err := p.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	testSearch(t, p)
+}
+
+func testSearch(t *testing.T, s searcher) {
+	// Each run has its own owner, so runs against the same database don't
+	// see each other's todos.
+	owner := fmt.Sprintf("search-test-%d", time.Now().UnixNano())
+	ctx := auth.NewContext(context.Background(), auth.Principal{Subject: owner})
+
+	ids := make(map[string]uint)
+	for _, td := range []todo.Todo{
+		{Title: "Buy milk", Description: "From the corner shop"},
+		{Title: "Call mom", Description: "Ask about milk prices"},
+		{Title: "Pay bills", Description: "Electricity and water"},
+		{Title: "Tom & Jerry milk", Description: ""},
+	} {
+		created, err := s.Create(ctx, td)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[td.Title] = created.ID
+	}
+
+	tests := []struct {
+		query         string
+		limit, offset int
+		want          []string
+		titleSnippet  string
+	}{
+		{query: "milk", want: []string{"Buy milk", "Tom & Jerry milk", "Call mom"}},
+		{query: "MILK", want: []string{"Buy milk", "Tom & Jerry milk", "Call mom"}},
+		{query: "milk", limit: 2, offset: 1, want: []string{"Tom & Jerry milk", "Call mom"}},
+		{query: "buy milk", want: []string{"Buy milk"}, titleSnippet: "<b>Buy</b> <b>milk</b>"},
+		{query: "milk prices", want: []string{"Call mom"}, titleSnippet: "Call mom"},
+		{query: "jerry", want: []string{"Tom & Jerry milk"}, titleSnippet: "Tom &amp; <b>Jerry</b> milk"},
+		{query: "milk bills", want: nil},
+		{query: "mil", want: nil},
+		{query: "bread", want: nil},
+		{query: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		limit := tt.limit
+		if limit == 0 {
+			limit = 10
+		}
+		results, err := s.Search(ctx, tt.query, limit, tt.offset)
+		if err != nil {
+			t.Fatalf("Search(%q): %v", tt.query, err)
+		}
+
+		var got []uint
+		for _, r := range results {
+			got = append(got, r.ID)
+		}
+		var want []uint
+		for _, title := range tt.want {
+			want = append(want, ids[title])
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Search(%q, %d, %d) = %v, want %v", tt.query, limit, tt.offset, got, want)
+			continue
+		}
+		if tt.titleSnippet != "" && results[0].TitleSnippet != tt.titleSnippet {
+			t.Errorf("Search(%q) title snippet = %q, want %q", tt.query, results[0].TitleSnippet, tt.titleSnippet)
+		}
+	}
+}
+
+// TestMemoryStoreSearchDoesNotStem pins down where the memory store
+// differs from postgres, which finds "prices" for "price".
+func TestMemoryStoreSearchDoesNotStem(t *testing.T) {
+	m := NewMemoryStore()
+	ctx := context.Background()
+	if _, err := m.Create(ctx, todo.Todo{Title: "Compare prices"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for query, want := range map[string]int{"prices": 1, "price": 0} {
+		results, err := m.Search(ctx, query, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != want {
+			t.Errorf("Search(%q) found %d todos, want %d", query, len(results), want)
+		}
+	}
+}
+
+func TestHighlightEscapes(t *testing.T) {
+	tests := []struct {
+		s     string
+		terms []string
+		want  string
+	}{
+		{"Buy milk", []string{"milk"}, "Buy <b>milk</b>"},
+		{"<script>milk</script>", []string{"milk"}, "&lt;script&gt;<b>milk</b>&lt;/script&gt;"},
+		{`"a" & 'b'`, []string{"a"}, `&#34;<b>a</b>&#34; &amp; &#39;b&#39;`},
+	}
+
+	for _, tt := range tests {
+		if got := highlight(tt.s, tt.terms); got != tt.want {
+			t.Errorf("highlight(%q, %q) = %q, want %q", tt.s, tt.terms, got, tt.want)
+		}
+	}
+}
+
+func TestMarkSnippet(t *testing.T) {
+	got := markSnippet("<i>" + startSel + "milk" + stopSel + "</i> & eggs")
+	want := "&lt;i&gt;<b>milk</b>&lt;/i&gt; &amp; eggs"
+	if got != want {
+		t.Errorf("markSnippet() = %q, want %q", got, want)
+	}
+}
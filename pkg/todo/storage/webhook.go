@@ -0,0 +1,317 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+	"github.com/jinzhu/gorm"
+)
+
+// ErrWebhookNotFound is returned for webhooks that don't exist or that the
+// caller doesn't own.
+var ErrWebhookNotFound = errors.New("Webhook not found")
+
+// CreateWebhook saves the webhook, owned by the caller, in memory.
+func (m *MemoryStore) CreateWebhook(ctx context.Context, w todo.Webhook) (todo.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w.ID = m.nextWebhookID
+	w.OwnerID = ownerID(ctx)
+	w.CreatedAt = time.Now()
+	m.webhooks[w.ID] = w
+	m.nextWebhookID++
+
+	return w, nil
+}
+
+// ListWebhooks fetches the caller's webhooks from memory.
+func (m *MemoryStore) ListWebhooks(ctx context.Context) ([]todo.Webhook, error) {
+	return m.OwnerWebhooks(ctx, ownerID(ctx))
+}
+
+// OwnerWebhooks fetches the webhooks of owner from memory.
+func (m *MemoryStore) OwnerWebhooks(ctx context.Context, owner string) ([]todo.Webhook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ww := make([]todo.Webhook, 0)
+	for _, w := range m.webhooks {
+		if w.OwnerID == owner {
+			ww = append(ww, w)
+		}
+	}
+	sort.Slice(ww, func(i, j int) bool { return ww[i].ID < ww[j].ID })
+
+	return ww, nil
+}
+
+// WebhookByID fetches a webhook of any user from memory.
+func (m *MemoryStore) WebhookByID(ctx context.Context, id uint) (todo.Webhook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	w, ok := m.webhooks[id]
+	if !ok {
+		return todo.Webhook{}, ErrWebhookNotFound
+	}
+	return w, nil
+}
+
+// DeleteWebhook removes a webhook owned by the caller and its deliveries
+// from memory.
+func (m *MemoryStore) DeleteWebhook(ctx context.Context, id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if w, ok := m.webhooks[id]; !ok || w.OwnerID != ownerID(ctx) {
+		return ErrWebhookNotFound
+	}
+	delete(m.webhooks, id)
+	for did, d := range m.deliveries {
+		if d.WebhookID == id {
+			delete(m.deliveries, did)
+		}
+	}
+
+	return nil
+}
+
+// ListDeliveries fetches the deliveries to a webhook owned by the caller
+// from memory, most recent first, only those with status if it isn't
+// empty.
+func (m *MemoryStore) ListDeliveries(ctx context.Context, webhookID uint, status string, limit, offset int) ([]todo.WebhookDelivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if w, ok := m.webhooks[webhookID]; !ok || w.OwnerID != ownerID(ctx) {
+		return nil, ErrWebhookNotFound
+	}
+
+	dd := make([]todo.WebhookDelivery, 0)
+	for _, d := range m.deliveries {
+		if d.WebhookID == webhookID && (status == "" || d.Status == status) {
+			dd = append(dd, d)
+		}
+	}
+	sort.Slice(dd, func(i, j int) bool { return dd[i].ID > dd[j].ID })
+
+	if offset >= len(dd) {
+		return []todo.WebhookDelivery{}, nil
+	}
+	dd = dd[offset:]
+	if len(dd) > limit {
+		dd = dd[:limit]
+	}
+
+	return dd, nil
+}
+
+// CreateDeliveries saves the deliveries in memory, skipping those of an
+// event that was already delivered to the same webhook.
+func (m *MemoryStore) CreateDeliveries(ctx context.Context, dd []todo.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	type eventID struct {
+		webhook uint
+		event   string
+	}
+	seen := make(map[eventID]bool, len(m.deliveries))
+	for _, d := range m.deliveries {
+		seen[eventID{d.WebhookID, d.EventID}] = true
+	}
+
+	now := time.Now()
+	for _, d := range dd {
+		if seen[eventID{d.WebhookID, d.EventID}] {
+			continue
+		}
+		seen[eventID{d.WebhookID, d.EventID}] = true
+		d.ID = m.nextDeliveryID
+		d.CreatedAt = now
+		d.UpdatedAt = now
+		m.deliveries[d.ID] = d
+		m.nextDeliveryID++
+	}
+
+	return nil
+}
+
+// ClaimDeliveries fetches at most limit pending deliveries due by now from
+// memory, pushing their next attempt back by lease so that they aren't
+// claimed again while they are attempted.
+func (m *MemoryStore) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]todo.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dd := make([]todo.WebhookDelivery, 0)
+	for _, d := range m.deliveries {
+		if d.Status == todo.DeliveryPending && !d.NextAttemptAt.After(now) {
+			dd = append(dd, d)
+		}
+	}
+	sort.Slice(dd, func(i, j int) bool { return dd[i].NextAttemptAt.Before(dd[j].NextAttemptAt) })
+	if len(dd) > limit {
+		dd = dd[:limit]
+	}
+
+	for i := range dd {
+		dd[i].NextAttemptAt = now.Add(lease)
+		m.deliveries[dd[i].ID] = dd[i]
+	}
+
+	return dd, nil
+}
+
+// UpdateDelivery saves the outcome of an attempt at d in memory.
+func (m *MemoryStore) UpdateDelivery(ctx context.Context, d todo.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The webhook may have been deleted during the attempt.
+	if _, ok := m.deliveries[d.ID]; !ok {
+		return nil
+	}
+	d.UpdatedAt = time.Now()
+	m.deliveries[d.ID] = d
+
+	return nil
+}
+
+// CreateWebhook saves the webhook, owned by the caller, in postgres.
+func (p *PostgresStore) CreateWebhook(ctx context.Context, w todo.Webhook) (todo.Webhook, error) {
+	w.OwnerID = ownerID(ctx)
+	err := p.db(ctx).Create(&w).Error
+	return w, err
+}
+
+// ListWebhooks fetches the caller's webhooks from postgres.
+func (p *PostgresStore) ListWebhooks(ctx context.Context) ([]todo.Webhook, error) {
+	return p.OwnerWebhooks(ctx, ownerID(ctx))
+}
+
+// OwnerWebhooks fetches the webhooks of owner from postgres.
+func (p *PostgresStore) OwnerWebhooks(ctx context.Context, owner string) ([]todo.Webhook, error) {
+	ww := make([]todo.Webhook, 0)
+	err := p.db(ctx).Where("owner_id = ?", owner).Order("id").Find(&ww).Error
+	return ww, err
+}
+
+// WebhookByID fetches a webhook of any user from postgres.
+func (p *PostgresStore) WebhookByID(ctx context.Context, id uint) (todo.Webhook, error) {
+	var w todo.Webhook
+	if err := p.db(ctx).First(&w, id).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return w, ErrWebhookNotFound
+		}
+		return w, err
+	}
+	return w, nil
+}
+
+// DeleteWebhook removes a webhook owned by the caller and its deliveries
+// from postgres.
+func (p *PostgresStore) DeleteWebhook(ctx context.Context, id uint) error {
+	return p.transaction(ctx, func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND owner_id = ?", id, ownerID(ctx)).Delete(&todo.Webhook{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrWebhookNotFound
+		}
+		return tx.Where("webhook_id = ?", id).Delete(&todo.WebhookDelivery{}).Error
+	})
+}
+
+// ListDeliveries fetches the deliveries to a webhook owned by the caller
+// from postgres, most recent first, only those with status if it isn't
+// empty.
+func (p *PostgresStore) ListDeliveries(ctx context.Context, webhookID uint, status string, limit, offset int) ([]todo.WebhookDelivery, error) {
+	var w todo.Webhook
+	if err := p.db(ctx).Where("owner_id = ?", ownerID(ctx)).First(&w, webhookID).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+
+	q := p.db(ctx).Where("webhook_id = ?", webhookID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	dd := make([]todo.WebhookDelivery, 0)
+	err := q.Order("id DESC").Limit(limit).Offset(offset).Find(&dd).Error
+	return dd, err
+}
+
+// CreateDeliveries saves the deliveries in postgres, skipping those of an
+// event that was already delivered to the same webhook.
+func (p *PostgresStore) CreateDeliveries(ctx context.Context, dd []todo.WebhookDelivery) error {
+	now := time.Now()
+	return p.transaction(ctx, func(tx *gorm.DB) error {
+		// gorm's Create scans the id the insert returns, and fails when a
+		// conflict leaves none.
+		for _, d := range dd {
+			err := tx.Exec(`
+				INSERT INTO webhook_deliveries (webhook_id, event_id, event, payload, status,
+					attempts, response_code, last_error, next_attempt_at, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (webhook_id, event_id) DO NOTHING`,
+				d.WebhookID, d.EventID, d.Event, d.Payload, d.Status,
+				d.Attempts, d.ResponseCode, d.LastError, d.NextAttemptAt, now, now).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ClaimDeliveries fetches at most limit pending deliveries due by now from
+// postgres, pushing their next attempt back by lease so that neither this
+// nor another server claims them again while they are attempted.
+func (p *PostgresStore) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]todo.WebhookDelivery, error) {
+	dd := make([]todo.WebhookDelivery, 0)
+	err := p.db(ctx).Raw(`
+		UPDATE webhook_deliveries SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(lease), todo.DeliveryPending, now, limit).Scan(&dd).Error
+	return dd, err
+}
+
+// UpdateDelivery saves the outcome of an attempt at d in postgres.
+func (p *PostgresStore) UpdateDelivery(ctx context.Context, d todo.WebhookDelivery) error {
+	return p.db(ctx).Model(&todo.WebhookDelivery{ID: d.ID}).Updates(map[string]interface{}{
+		"status":          d.Status,
+		"attempts":        d.Attempts,
+		"response_code":   d.ResponseCode,
+		"last_error":      d.LastError,
+		"next_attempt_at": d.NextAttemptAt,
+	}).Error
+}
+
+// transaction runs fn in a transaction, which is committed if fn returns
+// nil and rolled back otherwise.
+func (p *PostgresStore) transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	tx := p.db(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
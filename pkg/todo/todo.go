@@ -9,12 +9,50 @@ import (
 // Todo represents a todo item.
 type Todo struct {
 	gorm.Model
+	OwnerID     string `gorm:"not null;default:'';index"`
 	Title       string
 	Description string
-	Reminder    time.Time
+	Reminder    time.Time `gorm:"index"`
+	// Priority is a letter from A, the highest, to Z, or empty.
+	Priority string `gorm:"not null;default:''"`
 }
 
 // TableName sets Todo table name to `todos`.
 func (Todo) TableName() string {
 	return "todos"
 }
+
+// ValidPriority reports whether p is a priority, a letter from A to Z, or
+// empty.
+func ValidPriority(p string) bool {
+	return p == "" || len(p) == 1 && p[0] >= 'A' && p[0] <= 'Z'
+}
+
+// SearchResult is a todo item matched by a full-text search.
+type SearchResult struct {
+	Todo
+	Rank               float64
+	TitleSnippet       string
+	DescriptionSnippet string
+}
+
+// IdempotencyKey records the response to the first request a caller made
+// with an idempotency key, which is replayed to retries of the request.
+type IdempotencyKey struct {
+	OwnerID string `gorm:"primary_key"`
+	Key     string `gorm:"primary_key"`
+	Method  string `gorm:"not null"`
+	// RequestHash tells retries apart from other requests reusing the key.
+	RequestHash []byte
+	// Response is only set once Completed, until then the first request
+	// is still being handled.
+	Response  []byte
+	Completed bool `gorm:"not null;default:false"`
+	CreatedAt time.Time
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// TableName sets IdempotencyKey table name to `idempotency_keys`.
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
@@ -0,0 +1,92 @@
+package todo
+
+import (
+	"strings"
+	"time"
+)
+
+// Events that webhooks are notified of.
+const (
+	EventCreated  = "todo.created"
+	EventUpdated  = "todo.updated"
+	EventDeleted  = "todo.deleted"
+	EventReminder = "todo.reminder"
+)
+
+// Events are all the events.
+var Events = []string{EventCreated, EventUpdated, EventDeleted, EventReminder}
+
+// Event is a change to a todo, or its reminder coming up.
+type Event struct {
+	// ID is unique per event, a reminder has the same one every time it
+	// is found due.
+	ID        string
+	Type      string
+	OwnerID   string
+	Todo      Todo
+	CreatedAt time.Time
+}
+
+// Webhook is an endpoint that the events of its owner are posted to.
+type Webhook struct {
+	ID      uint   `gorm:"primary_key"`
+	OwnerID string `gorm:"not null;default:'';index"`
+	URL     string `gorm:"not null"`
+	// Events are the comma separated events posted, all of them if empty.
+	Events string `gorm:"not null;default:''"`
+	// Secret is the key the payloads are signed with.
+	Secret    string `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+// TableName sets Webhook table name to `webhooks`.
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Subscribes reports whether event is posted to w.
+func (w Webhook) Subscribes(event string) bool {
+	if w.Events == "" {
+		return true
+	}
+	for _, e := range strings.Split(w.Events, ",") {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Statuses of a WebhookDelivery.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	// DeliveryDead is the dead letter of a delivery that failed every
+	// attempt.
+	DeliveryDead = "dead"
+)
+
+// WebhookDelivery is an event posted, or to be posted, to a webhook, and
+// the outcome of the last attempt.
+type WebhookDelivery struct {
+	ID        uint   `gorm:"primary_key"`
+	WebhookID uint   `gorm:"not null;unique_index:idx_webhook_deliveries_event"`
+	EventID   string `gorm:"not null;unique_index:idx_webhook_deliveries_event"`
+	Event     string `gorm:"not null"`
+	// Payload is the JSON body posted.
+	Payload      []byte
+	Status       string `gorm:"not null;index"`
+	Attempts     int    `gorm:"not null;default:0"`
+	ResponseCode int    `gorm:"not null;default:0"`
+	LastError    string `gorm:"not null;default:''"`
+	// NextAttemptAt is when a pending delivery is attempted next, also
+	// pushed back while an attempt is in progress.
+	NextAttemptAt time.Time `gorm:"not null;index"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TableName sets WebhookDelivery table name to `webhook_deliveries`.
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
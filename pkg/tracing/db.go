@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/jinzhu/gorm"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	contextKey = "tracing:context"
+	spanKey    = "tracing:span"
+)
+
+// WithContext returns a copy of db whose queries are traced
+// as children of the span in ctx.
+func WithContext(db *gorm.DB, ctx context.Context) *gorm.DB {
+	return db.Set(contextKey, ctx)
+}
+
+// InstrumentDB starts a span for every query made through a db returned
+// by WithContext, with the SQL statement as an attribute.
+func InstrumentDB(db *gorm.DB) {
+	start := func(operation string) func(scope *gorm.Scope) {
+		return func(scope *gorm.Scope) {
+			v, ok := scope.Get(contextKey)
+			if !ok {
+				return
+			}
+			_, span := Tracer().Start(v.(context.Context), "db."+operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemPostgreSQL,
+					semconv.DBOperation(operation),
+					semconv.DBSQLTable(scope.TableName()),
+				),
+			)
+			scope.Set(spanKey, span)
+		}
+	}
+	end := func(scope *gorm.Scope) {
+		v, ok := scope.Get(spanKey)
+		if !ok {
+			return
+		}
+		span := v.(trace.Span)
+		span.SetAttributes(semconv.DBStatement(scope.SQL))
+		if scope.HasError() && !gorm.IsRecordNotFoundError(scope.DB().Error) {
+			span.RecordError(scope.DB().Error)
+			span.SetStatus(otelcodes.Error, scope.DB().Error.Error())
+		}
+		span.End()
+	}
+
+	cb := db.Callback()
+	cb.Create().Before("gorm:begin_transaction").Register("tracing:before_create", start("create"))
+	cb.Create().After("gorm:commit_or_rollback_transaction").Register("tracing:after_create", end)
+	cb.Update().Before("gorm:begin_transaction").Register("tracing:before_update", start("update"))
+	cb.Update().After("gorm:commit_or_rollback_transaction").Register("tracing:after_update", end)
+	cb.Delete().Before("gorm:begin_transaction").Register("tracing:before_delete", start("delete"))
+	cb.Delete().After("gorm:commit_or_rollback_transaction").Register("tracing:after_delete", end)
+	cb.Query().Before("gorm:query").Register("tracing:before_query", start("query"))
+	cb.Query().After("gorm:after_query").Register("tracing:after_query", end)
+	cb.RowQuery().Before("gorm:row_query").Register("tracing:before_row_query", start("row_query"))
+	cb.RowQuery().After("gorm:row_query").Register("tracing:after_row_query", end)
+}
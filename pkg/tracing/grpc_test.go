@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeStream answers RecvMsg with errs in turn.
+type fakeStream struct {
+	grpc.ClientStream
+	errs []error
+}
+
+func (s *fakeStream) RecvMsg(m interface{}) error {
+	err := s.errs[0]
+	s.errs = s.errs[1:]
+	return err
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	tests := []struct {
+		name          string
+		serverStreams bool
+		recv          []error
+		wantEndedAt   int // RecvMsg call after which the span ends, 0 for never
+		wantCode      codes.Code
+	}{
+		{"server streaming", true, []error{nil, nil, io.EOF}, 3, codes.OK},
+		{"server streaming failed", true, []error{nil, status.Error(codes.Unavailable, "gone")}, 2, codes.Unavailable},
+		{"client streaming", false, []error{nil}, 1, codes.OK},
+		{"server streaming not drained", true, []error{nil}, 0, codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+			otel.SetTracerProvider(tp)
+			defer tp.Shutdown(context.Background())
+
+			streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				return &fakeStream{errs: tt.recv}, nil
+			}
+			desc := &grpc.StreamDesc{ServerStreams: tt.serverStreams, ClientStreams: !tt.serverStreams}
+			cs, err := StreamClientInterceptor()(context.Background(), desc, nil, "/todo.v1.TodoService/Export", streamer)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := range tt.recv {
+				cs.RecvMsg(nil)
+				ended := len(sr.Ended())
+				if i+1 < tt.wantEndedAt && ended != 0 {
+					t.Fatalf("span ended after RecvMsg %d, want after %d", i+1, tt.wantEndedAt)
+				}
+			}
+
+			ended := sr.Ended()
+			if tt.wantEndedAt == 0 {
+				if len(ended) != 0 {
+					t.Fatalf("span ended before the stream did")
+				}
+				return
+			}
+			if len(ended) != 1 {
+				t.Fatalf("got %d ended spans, want 1", len(ended))
+			}
+			var code int64 = -1
+			for _, kv := range ended[0].Attributes() {
+				if kv.Key == "rpc.grpc.status_code" {
+					code = kv.Value.AsInt64()
+				}
+			}
+			if code != int64(tt.wantCode) {
+				t.Errorf("status code = %d, want %d", code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"context"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/dikaeinstein/prototodo"
+
+// Tracer returns the tracer spans of this module are started with.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Init installs a global tracer provider for serviceName and a W3C trace
+// context propagator. Spans are exported over OTLP/gRPC to otlpEndpoint,
+// e.g. http://localhost:4317. Without an endpoint they are written to
+// fallback as JSON, or dropped if it is nil.
+// The returned function flushes pending spans and must be called on exit.
+func Init(ctx context.Context, serviceName, otlpEndpoint string, fallback io.Writer) (func(context.Context) error, error) {
+	var opts []sdktrace.TracerProviderOption
+	switch {
+	case otlpEndpoint != "":
+		exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpointURL(otlpEndpoint))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	case fallback != nil:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(fallback))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(append(opts, sdktrace.WithResource(res))...)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return tp.Shutdown, nil
+}
@@ -0,0 +1,170 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/client"
+	pb "github.com/dikaeinstein/prototodo/pkg/proto"
+	"github.com/gdamore/tcell"
+	"github.com/golang/protobuf/ptypes"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+const (
+	fieldTitle = iota
+	fieldDescription
+	fieldReminder
+)
+
+// field is a single-line text input.
+type field struct {
+	label   string
+	initial string
+	value   []rune
+	cursor  int
+}
+
+func (f *field) text() string {
+	return string(f.value)
+}
+
+func (f *field) changed() bool {
+	return f.text() != f.initial
+}
+
+// form edits the title, description and reminder of a new or existing todo.
+type form struct {
+	id     int64
+	fields []*field
+	focus  int
+}
+
+// newForm returns a form for t, or for a new todo if t is nil.
+func newForm(t *pb.Todo) *form {
+	f := &form{}
+	var title, description, reminder string
+	if t != nil {
+		f.id = t.Id
+		title, description, reminder = t.Title, t.Description, formatTime(t.Reminder)
+	}
+	for _, fd := range []struct{ label, value string }{
+		{"Title", title},
+		{"Description", description},
+		{"Reminder", reminder},
+	} {
+		v := []rune(fd.value)
+		f.fields = append(f.fields, &field{label: fd.label, initial: fd.value, value: v, cursor: len(v)})
+	}
+	return f
+}
+
+// todo returns the todo to create, or the changed fields of the todo to
+// update, since the service leaves fields that are empty unchanged.
+func (f *form) todo(now time.Time) (*pb.Todo, error) {
+	t := &pb.Todo{Id: f.id}
+	title, description, reminder := f.fields[fieldTitle], f.fields[fieldDescription], f.fields[fieldReminder]
+
+	if f.id == 0 && strings.TrimSpace(title.text()) == "" {
+		return nil, fmt.Errorf("the title is required")
+	}
+	if f.id == 0 || title.changed() {
+		t.Title = title.text()
+	}
+	if f.id == 0 || description.changed() {
+		t.Description = description.text()
+	}
+	if reminder.changed() && reminder.text() != "" {
+		r, err := client.ParseReminder(reminder.text(), now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reminder: %v", err)
+		}
+		ts, err := ptypes.TimestampProto(r)
+		if err != nil {
+			return nil, err
+		}
+		t.Reminder = ts
+	}
+	return t, nil
+}
+
+func (f *form) handle(ev *tcell.EventKey) {
+	fd := f.fields[f.focus]
+	switch ev.Key() {
+	case tcell.KeyTab, tcell.KeyDown:
+		f.focus = (f.focus + 1) % len(f.fields)
+	case tcell.KeyBacktab, tcell.KeyUp:
+		f.focus = (f.focus + len(f.fields) - 1) % len(f.fields)
+	case tcell.KeyLeft:
+		if fd.cursor > 0 {
+			fd.cursor--
+		}
+	case tcell.KeyRight:
+		if fd.cursor < len(fd.value) {
+			fd.cursor++
+		}
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		fd.cursor = 0
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		fd.cursor = len(fd.value)
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if fd.cursor > 0 {
+			fd.value = append(fd.value[:fd.cursor-1], fd.value[fd.cursor:]...)
+			fd.cursor--
+		}
+	case tcell.KeyDelete:
+		if fd.cursor < len(fd.value) {
+			fd.value = append(fd.value[:fd.cursor], fd.value[fd.cursor+1:]...)
+		}
+	case tcell.KeyCtrlU:
+		fd.value, fd.cursor = nil, 0
+	case tcell.KeyRune:
+		fd.value = append(fd.value[:fd.cursor], append([]rune{ev.Rune()}, fd.value[fd.cursor:]...)...)
+		fd.cursor++
+	}
+}
+
+// draw draws the form between rows y and maxY, showing the cursor in the
+// focused field.
+func (f *form) draw(s tcell.Screen, y, maxY, w int) {
+	heading := "New todo"
+	if f.id != 0 {
+		heading = fmt.Sprintf("Edit todo %d", f.id)
+	}
+	drawText(s, 1, y, w, styleHeading, heading)
+
+	const labelWidth = 13
+	for i, fd := range f.fields {
+		row := y + 2 + i*2
+		if row >= maxY {
+			break
+		}
+		drawText(s, 1, row, labelWidth, tcell.StyleDefault, fd.label)
+
+		x := 1 + labelWidth
+		style := tcell.StyleDefault.Underline(true)
+		for cx := x; cx < w-1; cx++ {
+			s.SetContent(cx, row, ' ', nil, style)
+		}
+
+		// Scroll the value so that the cursor stays visible.
+		width := w - 1 - x
+		start := 0
+		for runewidth.StringWidth(string(fd.value[start:fd.cursor])) >= width && start < fd.cursor {
+			start++
+		}
+		end := drawText(s, x, row, w-1, style, string(fd.value[start:]))
+		if i == f.focus {
+			cx := x + runewidth.StringWidth(string(fd.value[start:fd.cursor]))
+			if cx > end {
+				cx = end
+			}
+			s.ShowCursor(cx, row)
+		}
+	}
+	if row := y + 2 + len(f.fields)*2; row < maxY {
+		drawText(s, 1, row, w, tcell.StyleDefault,
+			"Reminders are entered as "+timeLayout+" or as a duration from now such as 2h.")
+	}
+}
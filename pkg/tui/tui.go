@@ -0,0 +1,430 @@
+// Package tui is a full-screen terminal client for the todo service.
+//
+// The App draws on any tcell.Screen, so it can be driven headlessly with a
+// tcell.SimulationScreen against an in-process server.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dikaeinstein/prototodo/pkg/client"
+	pb "github.com/dikaeinstein/prototodo/pkg/proto"
+	"github.com/gdamore/tcell"
+	"github.com/golang/protobuf/ptypes"
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+	runewidth "github.com/mattn/go-runewidth"
+	"google.golang.org/grpc/status"
+)
+
+// timeLayout is how reminders are shown and, in the editor, entered.
+const timeLayout = client.ReminderLayout
+
+type mode int
+
+const (
+	modeList mode = iota
+	modeEdit
+	modeConfirm
+)
+
+var (
+	styleBar      = tcell.StyleDefault.Reverse(true)
+	styleHeading  = tcell.StyleDefault.Bold(true)
+	styleSelected = tcell.StyleDefault.Reverse(true)
+	styleError    = tcell.StyleDefault.Foreground(tcell.ColorRed)
+)
+
+// App is the terminal UI. It lists the caller's todos, refreshing them
+// periodically, and edits, creates and deletes them.
+type App struct {
+	client  pb.TodoServiceClient
+	screen  tcell.Screen
+	timeout time.Duration
+	refresh time.Duration
+
+	todos     []*pb.Todo
+	selected  int
+	offset    int
+	loadedAt  time.Time
+	mode      mode
+	form      *form
+	message   string
+	messageOK bool
+
+	// deleting is the todo the delete prompt is for, which stays the same
+	// while the list is refreshed under the prompt.
+	deleting *pb.Todo
+}
+
+// New returns an App that calls client with the given timeout per RPC and
+// reloads the list every refresh interval, or never if it is zero.
+// The caller initializes the screen and finalizes it after Run returns.
+func New(client pb.TodoServiceClient, screen tcell.Screen, timeout, refresh time.Duration) *App {
+	return &App{client: client, screen: screen, timeout: timeout, refresh: refresh}
+}
+
+// Run shows the UI until the user quits or ctx is done.
+func (a *App) Run(ctx context.Context) error {
+	events := make(chan tcell.Event)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			ev := a.screen.PollEvent()
+			if ev == nil {
+				return
+			}
+			select {
+			case events <- ev:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var tick <-chan time.Time
+	if a.refresh > 0 {
+		t := time.NewTicker(a.refresh)
+		defer t.Stop()
+		tick = t.C
+	}
+
+	a.reload(ctx, 0)
+	for {
+		a.draw()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick:
+			a.reload(ctx, 0)
+		case ev := <-events:
+			if quit := a.handle(ctx, ev); quit {
+				return nil
+			}
+		}
+	}
+}
+
+// reload fetches the todos, keeping the selected todo selected, or
+// selecting the todo with id if it is set.
+func (a *App) reload(ctx context.Context, id int64) {
+	if id == 0 && a.selected < len(a.todos) {
+		id = a.todos[a.selected].Id
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+	resp, err := a.client.ReadAll(ctx, &pb.ReadAllRequest{})
+	if err != nil {
+		a.fail("failed to load todos", err)
+		return
+	}
+
+	a.todos = resp.Todos
+	a.loadedAt = time.Now()
+	a.selected = 0
+	for i, t := range a.todos {
+		if t.Id == id {
+			a.selected = i
+			break
+		}
+	}
+}
+
+func (a *App) handle(ctx context.Context, ev tcell.Event) bool {
+	switch ev := ev.(type) {
+	case *tcell.EventResize:
+		a.screen.Sync()
+	case *tcell.EventKey:
+		if ev.Key() == tcell.KeyCtrlC {
+			return true
+		}
+		switch a.mode {
+		case modeList:
+			return a.handleList(ctx, ev)
+		case modeEdit:
+			a.handleEdit(ctx, ev)
+		case modeConfirm:
+			a.handleConfirm(ctx, ev)
+		}
+	}
+	return false
+}
+
+func (a *App) handleList(ctx context.Context, ev *tcell.EventKey) bool {
+	a.message = ""
+	page := a.listHeight()
+
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return true
+	case tcell.KeyUp:
+		a.move(-1)
+	case tcell.KeyDown:
+		a.move(1)
+	case tcell.KeyPgUp:
+		a.move(-page)
+	case tcell.KeyPgDn:
+		a.move(page)
+	case tcell.KeyHome:
+		a.move(-len(a.todos))
+	case tcell.KeyEnd:
+		a.move(len(a.todos))
+	case tcell.KeyEnter:
+		a.edit()
+	case tcell.KeyDelete:
+		a.confirm()
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'q':
+			return true
+		case 'k':
+			a.move(-1)
+		case 'j':
+			a.move(1)
+		case 'g':
+			a.move(-len(a.todos))
+		case 'G':
+			a.move(len(a.todos))
+		case 'e':
+			a.edit()
+		case 'a', 'n':
+			a.mode = modeEdit
+			a.form = newForm(nil)
+		case 'd':
+			a.confirm()
+		case 'r':
+			a.reload(ctx, 0)
+		}
+	}
+	return false
+}
+
+func (a *App) move(n int) {
+	a.selected += n
+	if a.selected >= len(a.todos) {
+		a.selected = len(a.todos) - 1
+	}
+	if a.selected < 0 {
+		a.selected = 0
+	}
+}
+
+func (a *App) current() *pb.Todo {
+	if a.selected >= len(a.todos) {
+		return nil
+	}
+	return a.todos[a.selected]
+}
+
+func (a *App) edit() {
+	if t := a.current(); t != nil {
+		a.mode = modeEdit
+		a.form = newForm(t)
+	}
+}
+
+func (a *App) confirm() {
+	if t := a.current(); t != nil {
+		a.mode = modeConfirm
+		a.deleting = t
+	}
+}
+
+func (a *App) handleConfirm(ctx context.Context, ev *tcell.EventKey) {
+	t := a.deleting
+	a.mode, a.deleting = modeList, nil
+	if ev.Key() != tcell.KeyRune || (ev.Rune() != 'y' && ev.Rune() != 'Y') {
+		a.info("not deleted")
+		return
+	}
+
+	rctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+	if _, err := a.client.Delete(rctx, &pb.DeleteRequest{Id: t.Id, IdempotencyKey: client.NewIdempotencyKey()}); err != nil {
+		a.fail(fmt.Sprintf("failed to delete %d", t.Id), err)
+		return
+	}
+	a.info(fmt.Sprintf("deleted %d", t.Id))
+	i := a.selected
+	a.reload(ctx, 0)
+	a.selected = i
+	a.move(0)
+}
+
+func (a *App) handleEdit(ctx context.Context, ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		a.mode = modeList
+		a.form = nil
+	case tcell.KeyEnter:
+		a.save(ctx)
+	default:
+		a.form.handle(ev)
+	}
+}
+
+// save creates or updates the todo of the form and returns to the list.
+func (a *App) save(ctx context.Context) {
+	t, err := a.form.todo(time.Now())
+	if err != nil {
+		a.message, a.messageOK = err.Error(), false
+		return
+	}
+
+	rctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+	if t.Id == 0 {
+		resp, err := a.client.Create(rctx, &pb.CreateRequest{Todo: t, IdempotencyKey: client.NewIdempotencyKey()})
+		if err != nil {
+			a.fail("failed to create the todo", err)
+			return
+		}
+		a.info(fmt.Sprintf("created %d", resp.Todo.Id))
+		a.mode, a.form = modeList, nil
+		a.reload(ctx, resp.Todo.Id)
+		return
+	}
+
+	if _, err := a.client.Update(rctx, &pb.UpdateRequest{Todo: t, IdempotencyKey: client.NewIdempotencyKey()}); err != nil {
+		a.fail(fmt.Sprintf("failed to update %d", t.Id), err)
+		return
+	}
+	a.info(fmt.Sprintf("updated %d", t.Id))
+	a.mode, a.form = modeList, nil
+	a.reload(ctx, t.Id)
+}
+
+func (a *App) info(msg string) {
+	a.message, a.messageOK = msg, true
+}
+
+func (a *App) fail(msg string, err error) {
+	a.message, a.messageOK = msg+": "+status.Convert(err).Message(), false
+}
+
+// listHeight is the number of rows between the header and the status line.
+func (a *App) listHeight() int {
+	_, h := a.screen.Size()
+	if h < 5 {
+		return 1
+	}
+	return h - 4
+}
+
+func (a *App) draw() {
+	a.screen.Clear()
+	a.screen.HideCursor()
+	w, h := a.screen.Size()
+
+	fill(a.screen, 0, w, styleBar)
+	drawText(a.screen, 1, 0, w-1, styleBar, fmt.Sprintf("prototodo  %d todos", len(a.todos)))
+	if !a.loadedAt.IsZero() {
+		loaded := "loaded " + a.loadedAt.Format("15:04:05")
+		drawText(a.screen, w-len(loaded)-1, 0, w, styleBar, loaded)
+	}
+
+	if a.mode == modeEdit {
+		a.form.draw(a.screen, 2, h-2, w)
+	} else {
+		a.drawList(w)
+	}
+
+	switch {
+	case a.mode == modeConfirm:
+		t := a.deleting
+		drawText(a.screen, 1, h-2, w, styleHeading, fmt.Sprintf("Delete %d %q? (y/n)", t.Id, t.Title))
+	case a.message != "":
+		style := tcell.StyleDefault
+		if !a.messageOK {
+			style = styleError
+		}
+		drawText(a.screen, 1, h-2, w, style, a.message)
+	}
+
+	fill(a.screen, h-1, w, styleBar)
+	drawText(a.screen, 1, h-1, w, styleBar, a.help())
+	a.screen.Show()
+}
+
+func (a *App) help() string {
+	switch a.mode {
+	case modeEdit:
+		return "enter save  esc cancel  tab next field  ctrl-u clear field  an empty field keeps its value"
+	case modeConfirm:
+		return "y delete  any other key cancels"
+	}
+	return "↑/↓ move  enter edit  a add  d delete  r refresh  q quit"
+}
+
+func (a *App) drawList(w int) {
+	const idWidth, reminderWidth = 6, len(timeLayout) + 2
+	titleWidth := (w - idWidth - reminderWidth) / 2
+	titleX := idWidth + 1
+	descriptionX := titleX + titleWidth
+	reminderX := w - reminderWidth + 1
+
+	drawText(a.screen, 1, 1, titleX, styleHeading, "ID")
+	drawText(a.screen, titleX, 1, descriptionX, styleHeading, "TITLE")
+	drawText(a.screen, descriptionX, 1, reminderX, styleHeading, "DESCRIPTION")
+	drawText(a.screen, reminderX, 1, w, styleHeading, "REMINDER")
+
+	rows := a.listHeight()
+	if a.selected < a.offset {
+		a.offset = a.selected
+	}
+	if a.selected >= a.offset+rows {
+		a.offset = a.selected - rows + 1
+	}
+
+	for i := a.offset; i < len(a.todos) && i < a.offset+rows; i++ {
+		t := a.todos[i]
+		y := 2 + i - a.offset
+		style := tcell.StyleDefault
+		if i == a.selected {
+			style = styleSelected
+			fill(a.screen, y, w, style)
+		}
+		drawText(a.screen, 1, y, titleX-1, style, fmt.Sprint(t.Id))
+		drawText(a.screen, titleX, y, descriptionX-1, style, t.Title)
+		drawText(a.screen, descriptionX, y, reminderX-1, style, t.Description)
+		drawText(a.screen, reminderX, y, w, style, formatTime(t.Reminder))
+	}
+	if len(a.todos) == 0 && !a.loadedAt.IsZero() {
+		drawText(a.screen, 1, 2, w, tcell.StyleDefault, "No todos yet, press a to add one.")
+	}
+}
+
+func formatTime(ts *tspb.Timestamp) string {
+	if ts == nil {
+		return ""
+	}
+	t, err := ptypes.Timestamp(ts)
+	if err != nil || t.IsZero() || t.Unix() <= 0 {
+		return ""
+	}
+	return t.Local().Format(timeLayout)
+}
+
+// drawText draws s from x to at most maxX on row y, returning the column
+// after the last rune drawn.
+func drawText(s tcell.Screen, x, y, maxX int, style tcell.Style, text string) int {
+	for _, r := range text {
+		rw := runewidth.RuneWidth(r)
+		if x+rw > maxX {
+			break
+		}
+		s.SetContent(x, y, r, nil, style)
+		x += rw
+	}
+	return x
+}
+
+// fill paints row y with style.
+func fill(s tcell.Screen, y, w int, style tcell.Style) {
+	for x := 0; x < w; x++ {
+		s.SetContent(x, y, ' ', nil, style)
+	}
+}
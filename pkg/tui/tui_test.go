@@ -0,0 +1,210 @@
+package tui
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/dikaeinstein/prototodo/pkg/proto"
+	g "github.com/dikaeinstein/prototodo/pkg/protocol/grpc"
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+	"github.com/dikaeinstein/prototodo/pkg/todo/service"
+	"github.com/dikaeinstein/prototodo/pkg/todo/storage"
+	"github.com/gdamore/tcell"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type nopPublisher struct{}
+
+func (nopPublisher) Publish(context.Context, todo.Event) {}
+
+// newClient serves the todo service from a memory store in process and
+// returns a client of it.
+func newClient(t *testing.T) pb.TodoServiceClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	pb.RegisterTodoServiceServer(s, g.NewGRPCTodoHandler(service.New(storage.NewMemoryStore(), nopPublisher{})))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewTodoServiceClient(conn)
+}
+
+func create(t *testing.T, c pb.TodoServiceClient, title string) int64 {
+	t.Helper()
+	resp, err := c.Create(context.Background(), &pb.CreateRequest{Todo: &pb.Todo{Title: title}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.Todo.Id
+}
+
+// screen is a simulation screen whose contents can be read while the App
+// draws: GetContents returns the cells that Show writes to.
+type simScreen struct {
+	tcell.SimulationScreen
+	mu sync.Mutex
+}
+
+func (s *simScreen) Show() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.SimulationScreen.Show()
+}
+
+func (s *simScreen) Sync() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.SimulationScreen.Sync()
+}
+
+// text returns the rows of the screen.
+func (s *simScreen) text() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cells, w, h := s.GetContents()
+	var b strings.Builder
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if r := cells[y*w+x].Runes; len(r) > 0 {
+				b.WriteRune(r[0])
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// start runs an App on a simulation screen until the test ends.
+func start(t *testing.T, c pb.TodoServiceClient, refresh time.Duration) *simScreen {
+	t.Helper()
+
+	screen := &simScreen{SimulationScreen: tcell.NewSimulationScreen("UTF-8")}
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(80, 24)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- New(c, screen, time.Second, refresh).Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run() = %v", err)
+		}
+		screen.Fini()
+	})
+
+	return screen
+}
+
+// waitFor waits until the screen shows every one of want.
+func waitFor(t *testing.T, s *simScreen, want ...string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got := s.text()
+		missing := ""
+		for _, w := range want {
+			if !strings.Contains(got, w) {
+				missing = w
+				break
+			}
+		}
+		if missing == "" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("screen doesn't show %q:\n%s", missing, got)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func typeText(s *simScreen, text string) {
+	for _, r := range text {
+		s.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	c := newClient(t)
+	screen := start(t, c, 0)
+	waitFor(t, screen, "0 todos", "No todos yet")
+
+	typeText(screen, "a")
+	waitFor(t, screen, "Title")
+	typeText(screen, "milk")
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	waitFor(t, screen, "1 todos", "created 1", "milk")
+}
+
+func TestDeletePromptKeepsTodoWhileRefreshing(t *testing.T) {
+	c := newClient(t)
+	first := create(t, c, "first")
+	create(t, c, "second")
+	screen := start(t, c, 10*time.Millisecond)
+	waitFor(t, screen, "2 todos")
+
+	typeText(screen, "d")
+	waitFor(t, screen, `Delete 1 "first"? (y/n)`)
+
+	// The todo is deleted elsewhere and the list refreshed under the
+	// prompt, which now has the second todo selected.
+	if _, err := c.Delete(context.Background(), &pb.DeleteRequest{Id: first}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, screen, "1 todos", `Delete 1 "first"? (y/n)`)
+
+	typeText(screen, "y")
+	waitFor(t, screen, "failed to delete 1")
+
+	resp, err := c.ReadAll(context.Background(), &pb.ReadAllRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Todos) != 1 || resp.Todos[0].Title != "second" {
+		t.Errorf("todos = %v, want only the second one", resp.Todos)
+	}
+}
+
+func TestDeletePromptWithEmptyList(t *testing.T) {
+	c := newClient(t)
+	id := create(t, c, "only")
+	screen := start(t, c, 10*time.Millisecond)
+	waitFor(t, screen, "1 todos")
+
+	typeText(screen, "d")
+	waitFor(t, screen, `Delete 1 "only"? (y/n)`)
+	if _, err := c.Delete(context.Background(), &pb.DeleteRequest{Id: id}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, screen, "0 todos", `Delete 1 "only"? (y/n)`)
+
+	typeText(screen, "n")
+	waitFor(t, screen, "not deleted", "No todos yet")
+}
@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+// ErrForbiddenURL is returned for webhook URLs that aren't http or https
+// URLs or whose host doesn't resolve, or resolves to an address that events
+// may not be posted to. Posting to loopback, private, link-local,
+// multicast or unspecified addresses would let users reach the servers
+// next to this one.
+var ErrForbiddenURL = errors.New("webhook URL is not allowed")
+
+// privateNets are the blocks of private and reserved addresses, besides
+// those net.IP methods report. NAT64 prefixes are among them as they map
+// to IPv4 addresses, which may be private.
+var privateNets = mustParseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+	"64:ff9b:1::/48",
+	"fc00::/7",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nn := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		nn = append(nn, n)
+	}
+	return nn
+}
+
+// allowedIP reports whether events may be posted to ip.
+func allowedIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsMulticast() {
+		return false
+	}
+	for _, n := range privateNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL returns an error wrapping ErrForbiddenURL unless rawURL is an
+// http or https URL whose host only resolves to addresses that events may
+// be posted to.
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrForbiddenURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: the scheme must be http or https, got %q", ErrForbiddenURL, u.Scheme)
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("%w: the host is missing", ErrForbiddenURL)
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if !allowedIP(ip) {
+			return fmt.Errorf("%w: %s is not a public address", ErrForbiddenURL, host)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("%w: failed to resolve %s", ErrForbiddenURL, host)
+	}
+	for _, a := range addrs {
+		if !allowedIP(a.IP) {
+			return fmt.Errorf("%w: %s resolves to %s, which is not a public address",
+				ErrForbiddenURL, host, a.IP)
+		}
+	}
+	return nil
+}
+
+// checkDial refuses connections to addresses that events may not be
+// posted to. It runs after the host was resolved, so that hosts resolving
+// to other addresses than when they were checked are refused too.
+func checkDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !allowedIP(ip) {
+		return fmt.Errorf("%w: %s is not a public address", ErrForbiddenURL, host)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestAllowedIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"100.64.0.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"0.1.2.3", false},
+		{"192.0.0.8", false},
+		{"198.18.0.1", false},
+		{"198.19.255.255", false},
+		{"198.20.0.1", true},
+		{"224.0.0.1", false},
+		{"239.255.255.250", false},
+		{"255.255.255.255", false},
+		{"ff02::1", false},
+		{"ff0e::1", false},
+		{"64:ff9b::a00:1", false},
+		{"64:ff9b::5db8:d822", false},
+		{"64:ff9b:1::1", false},
+	}
+
+	for _, tt := range tests {
+		if got := allowedIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("allowedIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url       string
+		forbidden bool
+	}{
+		{"https://93.184.216.34/hook", false},
+		{"http://127.0.0.1:9090/metrics", true},
+		{"http://localhost/hook", true},
+		{"http://[::1]:8080/hook", true},
+		{"http://169.254.169.254/latest/meta-data", true},
+		{"http://198.18.0.1/hook", true},
+		{"http://224.0.0.1/hook", true},
+		{"http://[64:ff9b::a9fe:a9fe]/latest/meta-data", true},
+		{"ftp://93.184.216.34/hook", true},
+		{"file:///etc/passwd", true},
+		{"gopher://93.184.216.34:70/_", true},
+		{"http:///hook", true},
+		{"/hook", true},
+	}
+
+	for _, tt := range tests {
+		err := CheckURL(context.Background(), tt.url)
+		if got := errors.Is(err, ErrForbiddenURL); got != tt.forbidden {
+			t.Errorf("CheckURL(%q) = %v, want forbidden %v", tt.url, err, tt.forbidden)
+		}
+	}
+}
+
+func TestClientRefusesPrivateAddresses(t *testing.T) {
+	c := newClient(0)
+	_, err := c.Post("http://127.0.0.1:1/hook", "application/json", nil)
+	if !errors.Is(err, ErrForbiddenURL) {
+		t.Fatalf("Post to loopback = %v, want %v", err, ErrForbiddenURL)
+	}
+	if got := publicError(0, err); got != ErrForbiddenURL.Error() {
+		t.Errorf("publicError = %q, want %q", got, ErrForbiddenURL.Error())
+	}
+}
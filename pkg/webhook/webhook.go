@@ -0,0 +1,424 @@
+// Package webhook posts todo events to the webhooks their owners
+// registered. Payloads are JSON signed with HMAC-SHA256, and failed
+// deliveries are retried with exponential backoff until they succeed or
+// are dead-lettered after the last attempt.
+//
+// The X-Todo-Signature header of a delivery is sha256= and the hex
+// HMAC-SHA256, keyed with the webhook's secret, of the X-Todo-Timestamp
+// header, a dot and the body, which Verify checks. Webhook URLs must
+// resolve to public addresses, which is checked again on every
+// connection, and redirects are not followed.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dikaeinstein/prototodo/pkg/todo"
+	"go.uber.org/zap"
+)
+
+// Headers of the requests posting events.
+const (
+	// SignatureHeader is sha256= followed by the hex HMAC-SHA256, keyed
+	// with the secret of the webhook, of the timestamp, a dot and the body.
+	SignatureHeader = "X-Todo-Signature"
+	// TimestampHeader is the Unix time the request was signed at, which
+	// receivers should check is recent to reject replayed requests.
+	TimestampHeader = "X-Todo-Timestamp"
+	EventHeader     = "X-Todo-Event"
+	// DeliveryHeader is the id of the delivery, the same across retries.
+	DeliveryHeader = "X-Todo-Delivery"
+)
+
+const (
+	// pollInterval is how often due deliveries are looked for, besides
+	// right after an event is published.
+	pollInterval = time.Second
+	// batchSize is how many deliveries are attempted at once.
+	batchSize      = 20
+	initialBackoff = 10 * time.Second
+	maxBackoff     = time.Hour
+	// maxErrorLen truncates the errors kept in the delivery log.
+	maxErrorLen = 500
+)
+
+// Store keeps webhooks and their deliveries.
+type Store interface {
+	CreateWebhook(ctx context.Context, w todo.Webhook) (todo.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]todo.Webhook, error)
+	DeleteWebhook(ctx context.Context, id uint) error
+	ListDeliveries(ctx context.Context, webhookID uint, status string, limit, offset int) ([]todo.WebhookDelivery, error)
+
+	OwnerWebhooks(ctx context.Context, owner string) ([]todo.Webhook, error)
+	WebhookByID(ctx context.Context, id uint) (todo.Webhook, error)
+	CreateDeliveries(ctx context.Context, dd []todo.WebhookDelivery) error
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]todo.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, d todo.WebhookDelivery) error
+}
+
+// Dispatcher records a delivery of each published event to the webhooks
+// subscribed to it, and posts them in the background. Recording the
+// deliveries before posting them means they survive restarts.
+type Dispatcher struct {
+	store       Store
+	client      *http.Client
+	maxAttempts int
+	wake        chan struct{}
+	l           *zap.Logger
+}
+
+// NewDispatcher creates a Dispatcher that gives up on a delivery after
+// maxAttempts attempts, each of which may take timeout.
+func NewDispatcher(store Store, maxAttempts int, timeout time.Duration, l *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		store:       store,
+		client:      newClient(timeout),
+		maxAttempts: maxAttempts,
+		wake:        make(chan struct{}, 1),
+		l:           l,
+	}
+}
+
+// newClient creates the client posting events, which only connects to
+// public addresses and doesn't follow redirects, so that webhooks can't
+// reach the servers next to this one.
+func newClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   checkDial,
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			// Proxies are not used, they would be dialed instead of the
+			// webhooks.
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Timeout: timeout,
+	}
+}
+
+// CreateWebhook registers w for the caller, generating its secret unless
+// it has one. It returns an error wrapping ErrForbiddenURL if the URL of w
+// isn't public.
+func (d *Dispatcher) CreateWebhook(ctx context.Context, w todo.Webhook) (todo.Webhook, error) {
+	if err := CheckURL(ctx, w.URL); err != nil {
+		return w, err
+	}
+	if w.Secret == "" {
+		b := make([]byte, 32)
+		if _, err := crand.Read(b); err != nil {
+			return w, fmt.Errorf("failed to generate secret: %v", err)
+		}
+		w.Secret = hex.EncodeToString(b)
+	}
+	return d.store.CreateWebhook(ctx, w)
+}
+
+// ListWebhooks returns the caller's webhooks.
+func (d *Dispatcher) ListWebhooks(ctx context.Context) ([]todo.Webhook, error) {
+	return d.store.ListWebhooks(ctx)
+}
+
+// DeleteWebhook deletes a webhook of the caller and its deliveries.
+func (d *Dispatcher) DeleteWebhook(ctx context.Context, id uint) error {
+	return d.store.DeleteWebhook(ctx, id)
+}
+
+// ListDeliveries returns the delivery log of a webhook of the caller.
+func (d *Dispatcher) ListDeliveries(ctx context.Context, webhookID uint, status string, limit, offset int) ([]todo.WebhookDelivery, error) {
+	return d.store.ListDeliveries(ctx, webhookID, status, limit, offset)
+}
+
+// payload is the JSON body posted for an event.
+type payload struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Todo      payloadTodo `json:"todo"`
+}
+
+type payloadTodo struct {
+	ID          uint       `json:"id"`
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Priority    string     `json:"priority,omitempty"`
+	Reminder    *time.Time `json:"reminder,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+func newPayload(e todo.Event) payload {
+	t := e.Todo
+	return payload{
+		ID:        e.ID,
+		Type:      e.Type,
+		CreatedAt: e.CreatedAt.UTC(),
+		Todo: payloadTodo{
+			ID:          t.ID,
+			Title:       t.Title,
+			Description: t.Description,
+			Priority:    t.Priority,
+			Reminder:    timeOrNil(t.Reminder),
+			CreatedAt:   timeOrNil(t.CreatedAt),
+			UpdatedAt:   timeOrNil(t.UpdatedAt),
+		},
+	}
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+// Publish records a delivery of e to each webhook of its owner subscribed
+// to it. Failures are logged, they don't fail the change that caused e.
+func (d *Dispatcher) Publish(ctx context.Context, e todo.Event) {
+	l := d.l.With(zap.String("event_id", e.ID), zap.String("event", e.Type))
+
+	ww, err := d.store.OwnerWebhooks(ctx, e.OwnerID)
+	if err != nil {
+		l.Error("failed to fetch webhooks", zap.Error(err))
+		return
+	}
+	body, err := json.Marshal(newPayload(e))
+	if err != nil {
+		l.Error("failed to encode event", zap.Error(err))
+		return
+	}
+
+	var dd []todo.WebhookDelivery
+	for _, w := range ww {
+		if !w.Subscribes(e.Type) {
+			continue
+		}
+		dd = append(dd, todo.WebhookDelivery{
+			WebhookID:     w.ID,
+			EventID:       e.ID,
+			Event:         e.Type,
+			Payload:       body,
+			Status:        todo.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		})
+	}
+	if len(dd) == 0 {
+		return
+	}
+	if err := d.store.CreateDeliveries(ctx, dd); err != nil {
+		l.Error("failed to record deliveries", zap.Error(err))
+		return
+	}
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run posts due deliveries until stop is closed.
+func (d *Dispatcher) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+
+		// Keep going while there may be more deliveries due, unless the
+		// server is shutting down.
+		for d.deliverDue() == batchSize {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+		}
+	}
+}
+
+// deliverDue attempts a batch of due deliveries and returns its size.
+func (d *Dispatcher) deliverDue() int {
+	ctx := context.Background()
+	// Deliveries are claimed for longer than an attempt may take, so that
+	// they are only attempted again if this server dies meanwhile.
+	lease := d.client.Timeout + time.Minute
+	dd, err := d.store.ClaimDeliveries(ctx, time.Now(), lease, batchSize)
+	if err != nil {
+		d.l.Error("failed to claim deliveries", zap.Error(err))
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	for _, del := range dd {
+		wg.Add(1)
+		go func(del todo.WebhookDelivery) {
+			defer wg.Done()
+			d.deliver(ctx, del)
+		}(del)
+	}
+	wg.Wait()
+
+	return len(dd)
+}
+
+// deliver makes an attempt at del and records its outcome.
+func (d *Dispatcher) deliver(ctx context.Context, del todo.WebhookDelivery) {
+	l := d.l.With(
+		zap.Uint("webhook_id", del.WebhookID),
+		zap.Uint("delivery_id", del.ID),
+		zap.String("event", del.Event))
+
+	w, err := d.store.WebhookByID(ctx, del.WebhookID)
+	if err != nil {
+		// Deleted webhooks take their deliveries with them.
+		l.Warn("failed to fetch webhook of delivery", zap.Error(err))
+		return
+	}
+
+	del.Attempts++
+	code, err := d.post(ctx, w, del)
+	del.ResponseCode = code
+	switch {
+	case err == nil:
+		del.Status = todo.DeliverySucceeded
+		del.LastError = ""
+		l.Debug("delivered event", zap.Int("attempts", del.Attempts))
+	case del.Attempts >= d.maxAttempts:
+		del.Status = todo.DeliveryDead
+		del.LastError = publicError(code, err)
+		l.Warn("giving up on delivery", zap.Int("attempts", del.Attempts), zap.Error(err))
+	default:
+		del.LastError = publicError(code, err)
+		del.NextAttemptAt = time.Now().Add(backoff(del.Attempts))
+		l.Info("delivery failed, retrying",
+			zap.Int("attempts", del.Attempts),
+			zap.Time("next_attempt_at", del.NextAttemptAt),
+			zap.Error(err))
+	}
+
+	if err := d.store.UpdateDelivery(ctx, del); err != nil {
+		l.Error("failed to record delivery", zap.Error(err))
+	}
+}
+
+// post posts the payload of del to w, returning the status code of the
+// response, if any, and an error unless it is a 2xx.
+func (d *Dispatcher) post(ctx context.Context, w todo.Webhook, del todo.WebhookDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(del.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "prototodo-webhook/1.0")
+	req.Header.Set(EventHeader, del.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(del.ID), 10))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(w.Secret, timestamp, del.Payload))
+
+	resp, err := d.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Draining the body lets the connection be reused.
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// publicError describes the failure of an attempt for the delivery log,
+// which the owner of the webhook reads. Only the status of responses is
+// kept: errors of requests that got none may tell about the network of
+// this server, so they are only logged.
+func publicError(code int, err error) string {
+	var ne net.Error
+	switch {
+	case code != 0:
+		return truncate(err.Error())
+	case errors.Is(err, ErrForbiddenURL):
+		return ErrForbiddenURL.Error()
+	case errors.As(err, &ne) && ne.Timeout():
+		return "request timed out"
+	default:
+		return "request failed"
+	}
+}
+
+// Sign returns the signature of body sent at timestamp, the value of
+// SignatureHeader.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of body sent at
+// timestamp, for receivers of webhooks written in Go.
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// backoff returns how long to wait after the given failed attempt, from
+// half to all of initialBackoff doubled with every attempt, capped at
+// maxBackoff.
+func backoff(attempt int) time.Duration {
+	d := initialBackoff
+	for i := 1; i < attempt && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// truncate shortens s to maxErrorLen bytes without splitting a UTF-8
+// sequence, which postgres would reject.
+func truncate(s string) string {
+	if len(s) <= maxErrorLen {
+		return s
+	}
+	i := maxErrorLen
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return s[:i]
+}